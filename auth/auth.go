@@ -92,7 +92,7 @@ func extractBearerToken(ah string) (string, error) {
 
 	// use TrimPrefix instead of HasPrefix because TrimPrefix is faster.
 	if len(strings.TrimPrefix(ah, "Bearer ")) == n && len(strings.TrimPrefix(ah, "bearer ")) == n {
-		return "", fmt.Errorf("authorization header should start with bearer: %s*", ah[:15])
+		return "", fmt.Errorf("authorization header should start with bearer")
 	}
 
 	return ah[lenBearer:], nil
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,9 @@ type AzureManagedIdentityOption func(*azureFetchOption)
 
 type azureFetchOption struct {
 	maxAttempts int
+	resource    string
+	idParam     string // query parameter selecting a user-assigned identity
+	id          string
 }
 
 func WithMaxAttempts(n int) AzureManagedIdentityOption {
@@ -28,31 +32,77 @@ func WithMaxAttempts(n int) AzureManagedIdentityOption {
 	}
 }
 
+// WithResource sets the resource to get a token for, such as "https://vault.azure.net",
+// or its scope such as "https://vault.azure.net/.default".
+// The default is Azure Database ("https://ossrdbms-aad.database.windows.net").
+func WithResource(resource string) AzureManagedIdentityOption {
+	return func(o *azureFetchOption) {
+		o.resource = strings.TrimSuffix(resource, "/.default")
+	}
+}
+
 type AzureManagedIdentity struct {
 	AccessToken string
 	ExpiresOn   time.Time
 }
 
-// https://github.com/Azure/azure-sdk-for-go/blob/main/sdk/azidentity/TROUBLESHOOTING.md#verify-the-app-service-managed-identity-endpoint-is-available
-//
-//nolint:funlen
-func GetAzureManagedIdentity(ctx context.Context, opts ...AzureManagedIdentityOption) (*AzureManagedIdentity, error) {
-	const (
-		apiVersion          = "2019-08-01"
-		resource            = "https://ossrdbms-aad.database.windows.net" // also work with "https://management.core.windows.net/"
-		envIdentityEndpoint = "IDENTITY_ENDPOINT"
-		envIdentityHeader   = "IDENTITY_HEADER"
-	)
+const (
+	azureAPIVersion          = "2019-08-01"
+	azureDefaultResource     = "https://ossrdbms-aad.database.windows.net" // also work with "https://management.core.windows.net/"
+	azureEnvIdentityEndpoint = "IDENTITY_ENDPOINT"
+	azureEnvIdentityHeader   = "IDENTITY_HEADER"
+)
+
+// WithClientID selects the user-assigned managed identity by its client ID.
+// Without WithClientID, WithObjectID or WithResourceID, the system-assigned identity is used.
+// If more than one is given, the last one is used.
+func WithClientID(id string) AzureManagedIdentityOption {
+	return func(o *azureFetchOption) {
+		o.idParam, o.id = "client_id", id
+	}
+}
 
+// WithObjectID selects the user-assigned managed identity by its object (principal) ID.
+func WithObjectID(id string) AzureManagedIdentityOption {
+	return func(o *azureFetchOption) {
+		o.idParam, o.id = "object_id", id
+	}
+}
+
+// WithResourceID selects the user-assigned managed identity by its Azure resource ID,
+// e.g. "/subscriptions/.../resourceGroups/.../providers/Microsoft.ManagedIdentity/userAssignedIdentities/name".
+func WithResourceID(id string) AzureManagedIdentityOption {
+	return func(o *azureFetchOption) {
+		o.idParam, o.id = "mi_res_id", id
+	}
+}
+
+func newAzureFetchOption(opts []AzureManagedIdentityOption) azureFetchOption {
 	opt := azureFetchOption{
 		maxAttempts: 5, //nolint:mnd
+		resource:    azureDefaultResource,
+		idParam:     "",
+		id:          "",
 	}
 
 	for _, f := range opts {
 		f(&opt)
 	}
 
-	endpoint := os.Getenv(envIdentityEndpoint)
+	return opt
+}
+
+// GetAzureManagedIdentity gets a token for the resource of WithResource, Azure Database by default.
+// https://github.com/Azure/azure-sdk-for-go/blob/main/sdk/azidentity/TROUBLESHOOTING.md#verify-the-app-service-managed-identity-endpoint-is-available
+func GetAzureManagedIdentity(ctx context.Context, opts ...AzureManagedIdentityOption) (*AzureManagedIdentity, error) {
+	opt := newAzureFetchOption(opts)
+
+	return fetchAzureManagedIdentity(ctx, opt.resource, opt)
+}
+
+//nolint:funlen
+func fetchAzureManagedIdentity(ctx context.Context, resource string, opt azureFetchOption) (*AzureManagedIdentity, error) {
+	endpoint := os.Getenv(azureEnvIdentityEndpoint)
 	if endpoint == "" {
 		return nil, fmt.Errorf("%w: please set IDENTITY_ENDPOINT", ErrInvalidEndpoint)
 	}
@@ -64,9 +114,13 @@ func GetAzureManagedIdentity(ctx context.Context, opts ...AzureManagedIdentityOp
 
 	q := u.Query()
 
-	q.Add("api-version", apiVersion)
+	q.Add("api-version", azureAPIVersion)
 	q.Add("resource", resource)
 
+	if opt.id != "" {
+		q.Add(opt.idParam, opt.id)
+	}
+
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
@@ -74,7 +128,7 @@ func GetAzureManagedIdentity(ctx context.Context, opts ...AzureManagedIdentityOp
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	req.Header.Add("x-identity-header", os.Getenv(envIdentityHeader))
+	req.Header.Add("x-identity-header", os.Getenv(azureEnvIdentityHeader))
 
 	var (
 		res         *http.Response
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"testing"
 	"time"
@@ -148,9 +149,129 @@ func TestAzure_RunRefreshLoop_failUpdate(t *testing.T) {
 	}
 }
 
+func TestAzureManagedIdentitySource_Token(t *testing.T) {
+	t.Setenv("IDENTITY_ENDPOINT", "http://test")
+
+	ctx := context.Background()
+
+	f := &testFetcher{
+		status: 200,
+		body: &testFetcherBody{
+			AccessToken: "test token",
+			ExpiresOn:   strconv.Itoa(int(time.Now().Add(time.Hour).Unix())),
+		},
+	}
+
+	identity.SetFetcher(f)
+	defer identity.SetFetcher(nil)
+
+	var ts identity.TokenSource = identity.NewAzureManagedIdentitySource()
+
+	for _, scopes := range [][]string{nil, {"https://ossrdbms-aad.database.windows.net/.default"}} {
+		token, err := ts.Token(ctx, scopes...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if token.AccessToken != "test token" {
+			t.Errorf(`want "test token", got %q`, token.AccessToken)
+		}
+	}
+
+	for range 2 {
+		if _, err := ts.Token(ctx, "https://vault.azure.net/.default"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resources := make([]string, len(f.reqs))
+	for i, req := range f.reqs {
+		resources[i] = req.URL.Query().Get("resource")
+	}
+
+	want := []string{"https://ossrdbms-aad.database.windows.net", "https://vault.azure.net"}
+	if !slices.Equal(resources, want) {
+		t.Errorf("want tokens cached per resource %v, got requests for %v", want, resources)
+	}
+
+	if _, err := ts.Token(ctx, "a", "b"); !errors.Is(err, identity.ErrInvalidScope) {
+		t.Errorf("should return ErrInvalidScope: %v", err)
+	}
+}
+
+func TestAzure_GetAzureManagedIdentity_resource(t *testing.T) {
+	t.Setenv("IDENTITY_ENDPOINT", "http://test")
+
+	f := &testFetcher{
+		status: 200,
+		body: &testFetcherBody{
+			AccessToken: "test token",
+			ExpiresOn:   strconv.Itoa(int(time.Now().Add(time.Hour).Unix())),
+		},
+	}
+
+	identity.SetFetcher(f)
+	defer identity.SetFetcher(nil)
+
+	_, err := identity.GetAzureManagedIdentity(context.Background(), identity.WithResource("https://storage.azure.com/.default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.reqs[0].URL.Query().Get("resource"); got != "https://storage.azure.com" {
+		t.Errorf(`want "https://storage.azure.com", got %q`, got)
+	}
+}
+
+func TestAzure_GetAzureManagedIdentity_userAssigned(t *testing.T) {
+	t.Setenv("IDENTITY_ENDPOINT", "http://test")
+
+	const resID = "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/app"
+
+	tests := []struct {
+		name  string
+		opt   identity.AzureManagedIdentityOption
+		param string
+		want  string
+	}{
+		{"client id", identity.WithClientID("client"), "client_id", "client"},
+		{"object id", identity.WithObjectID("object"), "object_id", "object"},
+		{"resource id", identity.WithResourceID(resID), "mi_res_id", resID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &testFetcher{
+				status: 200,
+				body: &testFetcherBody{
+					AccessToken: "test token",
+					ExpiresOn:   strconv.Itoa(int(time.Now().Add(time.Hour).Unix())),
+				},
+			}
+
+			identity.SetFetcher(f)
+			defer identity.SetFetcher(nil)
+
+			if _, err := identity.GetAzureManagedIdentity(context.Background(), tt.opt); err != nil {
+				t.Fatal(err)
+			}
+
+			q := f.reqs[0].URL.Query()
+			if got := q.Get(tt.param); got != tt.want {
+				t.Errorf("%s: want %q, got %q", tt.param, tt.want, got)
+			}
+
+			if n := len(q); n != 3 {
+				t.Errorf("want api-version, resource and %s only, got %v", tt.param, q)
+			}
+		})
+	}
+}
+
 type testFetcher struct {
 	status int
 	body   *testFetcherBody
+	reqs   []*http.Request
 }
 
 type testFetcherBody struct {
@@ -158,7 +279,9 @@ type testFetcherBody struct {
 	ExpiresOn   string `json:"expires_on"`
 }
 
-func (f *testFetcher) Fetch(_ context.Context, _ *http.Request) (*http.Response, error) {
+func (f *testFetcher) Fetch(_ context.Context, req *http.Request) (*http.Response, error) {
+	f.reqs = append(f.reqs, req)
+
 	var b bytes.Buffer
 	if f.body != nil {
 		if err := json.NewEncoder(&b).Encode(f.body); err != nil {
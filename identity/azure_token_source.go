@@ -0,0 +1,92 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidScope = errors.New("invalid scope")
+
+// tokenRefreshMargin is how long before the expiry a cached token is refreshed.
+const tokenRefreshMargin = 5 * time.Minute
+
+// AzureManagedIdentitySource is a TokenSource getting tokens of the Azure managed identity.
+// Tokens are cached per resource until shortly before they expire, so one source can hold
+// tokens for Azure Database, Key Vault, Storage and so on at once.
+type AzureManagedIdentitySource struct {
+	opt azureFetchOption
+
+	mu     sync.Mutex
+	tokens map[string]*cachedToken // by resource
+}
+
+type cachedToken struct {
+	mu    sync.Mutex // held while fetching, so that a slow resource does not block the others
+	token *Token
+}
+
+var _ TokenSource = (*AzureManagedIdentitySource)(nil)
+
+func NewAzureManagedIdentitySource(opts ...AzureManagedIdentityOption) *AzureManagedIdentitySource {
+	//nolint:exhaustruct
+	return &AzureManagedIdentitySource{
+		opt:    newAzureFetchOption(opts),
+		tokens: make(map[string]*cachedToken),
+	}
+}
+
+// Token returns a token of the managed identity. The managed identity endpoint accepts one resource,
+// so scopes has at most one scope such as "https://vault.azure.net/.default".
+// No scopes means the resource of WithResource, Azure Database by default.
+func (s *AzureManagedIdentitySource) Token(ctx context.Context, scopes ...string) (*Token, error) {
+	resource, err := azureResource(scopes, s.opt.resource)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+
+	c, ok := s.tokens[resource]
+	if !ok {
+		c = new(cachedToken)
+		s.tokens[resource] = c
+	}
+
+	s.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token.valid(tokenRefreshMargin) {
+		return c.token, nil
+	}
+
+	mi, err := fetchAzureManagedIdentity(ctx, resource, s.opt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.token = &Token{AccessToken: mi.AccessToken, ExpiresOn: mi.ExpiresOn}
+
+	return c.token, nil
+}
+
+// azureResource converts the scope of Microsoft Entra ID to the resource of the managed identity endpoint.
+func azureResource(scopes []string, defaultResource string) (string, error) {
+	switch len(scopes) {
+	case 0:
+		return defaultResource, nil
+	case 1:
+		if scopes[0] == "" {
+			return "", fmt.Errorf("%w: empty scope", ErrInvalidScope)
+		}
+
+		return strings.TrimSuffix(scopes[0], "/.default"), nil
+	default:
+		return "", fmt.Errorf("%w: managed identity accepts one scope, got %d", ErrInvalidScope, len(scopes))
+	}
+}
@@ -0,0 +1,38 @@
+package identity
+
+import (
+	"context"
+	"time"
+)
+
+// Token is an access token and its expiry.
+type Token struct {
+	AccessToken string
+	ExpiresOn   time.Time
+}
+
+// TokenSource returns access tokens for the scopes, e.g. "https://vault.azure.net/.default".
+// Database drivers, pubsub and HTTP clients should depend on TokenSource rather than a provider,
+// so that they work with any provider and with a fake in tests.
+type TokenSource interface {
+	Token(ctx context.Context, scopes ...string) (*Token, error)
+}
+
+// TokenSourceFunc is a function implementing TokenSource.
+type TokenSourceFunc func(ctx context.Context, scopes ...string) (*Token, error)
+
+func (f TokenSourceFunc) Token(ctx context.Context, scopes ...string) (*Token, error) {
+	return f(ctx, scopes...)
+}
+
+// StaticTokenSource returns a TokenSource always returning t, e.g. for tests.
+func StaticTokenSource(t *Token) TokenSource {
+	return TokenSourceFunc(func(context.Context, ...string) (*Token, error) {
+		return t, nil
+	})
+}
+
+// valid reports whether t can be used for margin more.
+func (t *Token) valid(margin time.Duration) bool {
+	return t != nil && t.AccessToken != "" && time.Until(t.ExpiresOn) > margin
+}
@@ -0,0 +1,172 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrClosed = errors.New("writer closed")
+
+// OverflowPolicy decides what AsyncWriter does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// Overflow_Block blocks the caller until the queue has room (backpressure).
+	Overflow_Block OverflowPolicy = iota
+
+	// Overflow_Drop discards the entry and counts it in AsyncWriter.Dropped.
+	Overflow_Drop
+)
+
+type asyncOption struct {
+	queueSize int
+	policy    OverflowPolicy
+}
+
+type AsyncOption func(*asyncOption)
+
+func WithQueueSize(n int) AsyncOption {
+	return func(o *asyncOption) {
+		o.queueSize = n
+	}
+}
+
+func WithOverflowPolicy(p OverflowPolicy) AsyncOption {
+	return func(o *asyncOption) {
+		o.policy = p
+	}
+}
+
+type asyncItem struct {
+	b    []byte
+	done chan struct{} // not nil for flush requests
+}
+
+// AsyncWriter writes to the underlying writer in a background goroutine through a bounded queue.
+// Each Write is queued as a whole, so one entry is never split.
+// Call Flush or Close before exiting, otherwise queued entries are lost.
+type AsyncWriter struct {
+	w       io.Writer
+	policy  OverflowPolicy
+	queue   chan asyncItem
+	mu      sync.RWMutex // guards closed and sending to queue
+	closed  bool
+	done    chan struct{}
+	dropped atomic.Uint64
+	err     atomic.Pointer[error]
+}
+
+// NewAsyncWriter starts a goroutine writing to w. The default queue size is 1024 with Overflow_Block.
+func NewAsyncWriter(w io.Writer, opts ...AsyncOption) *AsyncWriter {
+	opt := asyncOption{
+		queueSize: 1024, //nolint:mnd
+		policy:    Overflow_Block,
+	}
+
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	//nolint:exhaustruct
+	aw := &AsyncWriter{
+		w:      w,
+		policy: opt.policy,
+		queue:  make(chan asyncItem, opt.queueSize),
+		done:   make(chan struct{}),
+	}
+
+	go aw.run()
+
+	return aw
+}
+
+func (aw *AsyncWriter) run() {
+	defer close(aw.done)
+
+	for item := range aw.queue {
+		if item.done != nil {
+			close(item.done)
+			continue
+		}
+
+		if _, err := aw.w.Write(item.b); err != nil {
+			aw.err.Store(&err)
+		}
+	}
+}
+
+// Write queues a copy of p.
+func (aw *AsyncWriter) Write(p []byte) (int, error) {
+	aw.mu.RLock()
+	defer aw.mu.RUnlock()
+
+	if aw.closed {
+		return 0, ErrClosed
+	}
+
+	item := asyncItem{b: append([]byte(nil), p...), done: nil}
+
+	if aw.policy == Overflow_Drop {
+		select {
+		case aw.queue <- item:
+		default:
+			aw.dropped.Add(1)
+		}
+
+		return len(p), nil
+	}
+
+	aw.queue <- item
+
+	return len(p), nil
+}
+
+// Dropped returns the number of entries discarded by Overflow_Drop.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return aw.dropped.Load()
+}
+
+// Flush waits until the entries queued before the call are written,
+// and returns the last error of the underlying writer.
+func (aw *AsyncWriter) Flush() error {
+	aw.mu.RLock()
+
+	if aw.closed {
+		aw.mu.RUnlock()
+		return aw.lastError()
+	}
+
+	done := make(chan struct{})
+	aw.queue <- asyncItem{b: nil, done: done}
+	aw.mu.RUnlock()
+
+	<-done
+
+	return aw.lastError()
+}
+
+// Close writes the queued entries and stops the goroutine. The underlying writer is not closed.
+func (aw *AsyncWriter) Close() error {
+	aw.mu.Lock()
+
+	if !aw.closed {
+		aw.closed = true
+		close(aw.queue)
+	}
+
+	aw.mu.Unlock()
+
+	<-aw.done
+
+	return aw.lastError()
+}
+
+func (aw *AsyncWriter) lastError() error {
+	if p := aw.err.Load(); p != nil {
+		return *p
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p) //nolint:wrapcheck
+}
+
+func TestAsyncWriter_flush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	aw := log.NewAsyncWriter(&buf)
+	l := log.New(log.WithOutput(aw), log.WithStructuredLogging(true))
+
+	for range 100 {
+		l.Println("hello")
+	}
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(buf.String(), "\n"); n != 100 {
+		t.Errorf("want 100 lines, got %d", n)
+	}
+
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := aw.Write([]byte("x")); !errors.Is(err, log.ErrClosed) {
+		t.Errorf("want ErrClosed, got %v", err)
+	}
+}
+
+func TestAsyncWriter_drop(t *testing.T) {
+	t.Parallel()
+
+	w := &blockingWriter{release: make(chan struct{})} //nolint:exhaustruct
+	aw := log.NewAsyncWriter(w, log.WithQueueSize(2), log.WithOverflowPolicy(log.Overflow_Drop))
+
+	for range 10 {
+		if _, err := aw.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(w.release)
+
+	if err := aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the goroutine may hold one entry while the queue holds two.
+	if d := aw.Dropped(); d < 7 || d > 8 {
+		t.Errorf("want 7 or 8 dropped, got %d", d)
+	}
+
+	if got := uint64(strings.Count(w.buf.String(), "\n")) + aw.Dropped(); got != 10 {
+		t.Errorf("written + dropped should be 10, got %d", got)
+	}
+}
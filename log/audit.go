@@ -0,0 +1,239 @@
+package log
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrInvalidAuditEvent = errors.New("invalid audit event")
+	ErrAuditChainBroken  = errors.New("audit chain broken")
+	ErrNoAuditLogger     = errors.New("no audit logger set")
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcome_Success AuditOutcome = "success"
+	AuditOutcome_Failure AuditOutcome = "failure"
+	AuditOutcome_Denied  AuditOutcome = "denied"
+)
+
+// AuditEvent is who did what to which resource, and how it ended.
+type AuditEvent struct {
+	Actor    string       `json:"actor"`    // e.g. the user ID or the service account
+	Action   string       `json:"action"`   // e.g. "account.read" or "lifestyle_journal.update"
+	Resource string       `json:"resource"` // e.g. "accounts/123"
+	Outcome  AuditOutcome `json:"outcome"`
+}
+
+// AuditRecord is an AuditEvent as written by AuditLogger, one JSON object per line.
+// Hash is the SHA-256 (or HMAC-SHA256 with WithAuditKey) of the record without Hash,
+// which includes PrevHash, so that an edited, inserted or deleted record breaks the chain.
+type AuditRecord struct {
+	AuditEvent
+
+	Time     time.Time `json:"time"`
+	Seq      uint64    `json:"seq"`
+	PrevHash string    `json:"prev_hash"`
+	Hash     string    `json:"hash,omitempty"`
+}
+
+type auditOption struct {
+	key      []byte
+	seq      uint64
+	prevHash string
+}
+
+type AuditOption func(*auditOption)
+
+// WithAuditKey chains records with HMAC-SHA256 of key, so that records cannot be forged
+// without the key. The same key must be passed to VerifyAuditLog.
+func WithAuditKey(key []byte) AuditOption {
+	return func(o *auditOption) {
+		o.key = key
+	}
+}
+
+// WithAuditChain continues the chain from the last record written before, e.g. by a previous process.
+func WithAuditChain(lastSeq uint64, lastHash string) AuditOption {
+	return func(o *auditOption) {
+		o.seq = lastSeq
+		o.prevHash = lastHash
+	}
+}
+
+// AuditLogger writes hash-chained AuditRecords to its own writer, apart from the operational logs.
+// The writer should be dedicated to audit records, e.g. a RotatingFile.
+type AuditLogger struct {
+	w   io.Writer
+	key []byte
+
+	mu       sync.Mutex
+	seq      uint64
+	prevHash string
+}
+
+func NewAuditLogger(w io.Writer, opts ...AuditOption) *AuditLogger {
+	var opt auditOption
+
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	//nolint:exhaustruct
+	return &AuditLogger{
+		w:        w,
+		key:      opt.key,
+		seq:      opt.seq,
+		prevHash: opt.prevHash,
+	}
+}
+
+var defaultAuditLogger atomic.Pointer[AuditLogger]
+
+// SetAuditLogger sets the AuditLogger used by Audit. nil unsets it.
+// There is no default, so that audit records never end up mixed with other output by accident.
+func SetAuditLogger(a *AuditLogger) {
+	defaultAuditLogger.Store(a)
+}
+
+// Audit writes ev with the AuditLogger set by SetAuditLogger, or returns ErrNoAuditLogger
+// if it is not set. See AuditLogger.Audit.
+func Audit(ev AuditEvent) error {
+	a := defaultAuditLogger.Load()
+	if a == nil {
+		return ErrNoAuditLogger
+	}
+
+	return a.Audit(ev)
+}
+
+// Audit writes ev as the next record of the chain. Unlike the operational logs, it returns the error
+// so that the caller can refuse the operation if it cannot be audited. The chain does not advance
+// if the write fails.
+func (a *AuditLogger) Audit(ev AuditEvent) error {
+	if err := ev.validate(); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rec := AuditRecord{
+		AuditEvent: ev,
+		Time:       time.Now().UTC(),
+		Seq:        a.seq + 1,
+		PrevHash:   a.prevHash,
+		Hash:       "",
+	}
+
+	h, err := rec.hash(a.key)
+	if err != nil {
+		return err
+	}
+
+	rec.Hash = h
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshal audit record: %w", err)
+	}
+
+	if _, err := a.w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("write audit record: %w", err)
+	}
+
+	a.seq, a.prevHash = rec.Seq, rec.Hash
+
+	return nil
+}
+
+// Last returns the sequence number and the hash of the last record, to be passed to WithAuditChain.
+func (a *AuditLogger) Last() (seq uint64, hash string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.seq, a.prevHash
+}
+
+func (ev *AuditEvent) validate() error {
+	if ev.Actor == "" || ev.Action == "" || ev.Resource == "" {
+		return fmt.Errorf("%w: actor, action and resource are required", ErrInvalidAuditEvent)
+	}
+
+	switch ev.Outcome {
+	case AuditOutcome_Success, AuditOutcome_Failure, AuditOutcome_Denied:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown outcome %q", ErrInvalidAuditEvent, ev.Outcome)
+	}
+}
+
+// hash returns the hash of rec without its Hash.
+func (rec AuditRecord) hash(key []byte) (string, error) {
+	rec.Hash = ""
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return "", fmt.Errorf("marshal audit record: %w", err)
+	}
+
+	var h hash.Hash
+	if key != nil {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+
+	h.Write(b)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyAuditLog reads the records written by AuditLogger from r and reports the first record
+// which is not chained to the previous one. key is the key of WithAuditKey or nil.
+// The first record may continue a chain, so compare its PrevHash with the last hash
+// of the previous file, if any, to detect the deletion of whole files.
+func VerifyAuditLog(r io.Reader, key []byte) (last AuditRecord, err error) { //nolint:nonamedreturns
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20) //nolint:mnd
+
+	for n := 1; sc.Scan(); n++ {
+		var rec AuditRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return last, fmt.Errorf("%w: line %d: %w", ErrAuditChainBroken, n, err)
+		}
+
+		h, err := rec.hash(key)
+		if err != nil {
+			return last, err
+		}
+
+		switch {
+		case !hmac.Equal([]byte(h), []byte(rec.Hash)):
+			return last, fmt.Errorf("%w: line %d: record modified", ErrAuditChainBroken, n)
+		case n > 1 && (rec.PrevHash != last.Hash || rec.Seq != last.Seq+1):
+			return last, fmt.Errorf("%w: line %d: records missing or reordered before seq %d",
+				ErrAuditChainBroken, n, rec.Seq)
+		}
+
+		last = rec
+	}
+
+	if err := sc.Err(); err != nil {
+		return last, fmt.Errorf("read audit log: %w", err)
+	}
+
+	return last, nil
+}
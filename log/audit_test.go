@@ -0,0 +1,104 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestAuditLogger(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("0123456789abcdef")
+
+	var buf bytes.Buffer
+
+	a := log.NewAuditLogger(&buf, log.WithAuditKey(key))
+
+	for _, ev := range []log.AuditEvent{
+		{Actor: "user-1", Action: "account.read", Resource: "accounts/1", Outcome: log.AuditOutcome_Success},
+		{Actor: "user-2", Action: "account.read", Resource: "accounts/1", Outcome: log.AuditOutcome_Denied},
+		{Actor: "batch", Action: "lifestyle_journal.update", Resource: "accounts/1/journals/9", Outcome: log.AuditOutcome_Failure},
+	} {
+		if err := a.Audit(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := a.Audit(log.AuditEvent{Actor: "user-1", Action: "account.read", Resource: "", Outcome: "ok"})
+	if !errors.Is(err, log.ErrInvalidAuditEvent) {
+		t.Errorf("want ErrInvalidAuditEvent, got %v", err)
+	}
+
+	last, err := log.VerifyAuditLog(bytes.NewReader(buf.Bytes()), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if seq, h := a.Last(); last.Seq != 3 || seq != 3 || last.Hash != h {
+		t.Errorf("want last seq 3 and hash %s, got %d %s", h, last.Seq, last.Hash)
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+
+	tests := []struct {
+		name string
+		log  string
+		key  []byte
+	}{
+		{"edited", lines[0] + strings.Replace(lines[1], "denied", "success", 1) + lines[2], key},
+		{"deleted", lines[0] + lines[2], key},
+		{"reordered", lines[1] + lines[0] + lines[2], key},
+		{"wrong key", buf.String(), []byte("another key.....")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := log.VerifyAuditLog(strings.NewReader(tt.log), tt.key); !errors.Is(err, log.ErrAuditChainBroken) {
+				t.Errorf("want ErrAuditChainBroken, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuditLogger_continueChain(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+
+	a := log.NewAuditLogger(&first)
+	_ = a.Audit(log.AuditEvent{Actor: "u", Action: "read", Resource: "r", Outcome: log.AuditOutcome_Success})
+
+	b := log.NewAuditLogger(&second, log.WithAuditChain(a.Last()))
+	_ = b.Audit(log.AuditEvent{Actor: "u", Action: "write", Resource: "r", Outcome: log.AuditOutcome_Success})
+
+	if _, err := log.VerifyAuditLog(strings.NewReader(first.String()+second.String()), nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ev := log.AuditEvent{Actor: "u", Action: "read", Resource: "r", Outcome: log.AuditOutcome_Success}
+
+	if err := log.Audit(ev); !errors.Is(err, log.ErrNoAuditLogger) {
+		t.Fatalf("want ErrNoAuditLogger, got %v", err)
+	}
+
+	var buf bytes.Buffer
+
+	log.SetAuditLogger(log.NewAuditLogger(&buf))
+	defer log.SetAuditLogger(nil)
+
+	if err := log.Audit(ev); err != nil {
+		t.Fatal(err)
+	}
+
+	if last, err := log.VerifyAuditLog(&buf, nil); err != nil || last.Seq != 1 {
+		t.Errorf("want one record, got seq %d: %v", last.Seq, err)
+	}
+}
@@ -0,0 +1,154 @@
+package log
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiFaint   = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+
+	severityWidth = len("EMERGENCY")
+)
+
+// isTerminal reports whether w is a terminal which accepts colors.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+func severityColor(s Severity) string {
+	switch {
+	case s >= Severity_CRITICAL:
+		return ansiBold + ansiMagenta
+	case s >= Severity_ERROR:
+		return ansiRed
+	case s >= Severity_WARNING:
+		return ansiYellow
+	case s >= Severity_NOTICE:
+		return ansiCyan
+	case s >= Severity_INFO:
+		return ansiGreen
+	default:
+		return ansiBlue
+	}
+}
+
+// appendConsole renders e as a human-friendly line:
+//
+//	15:04:05.000 WARNING   message  key=value key2="value 2"
+//
+// Multi-line values such as stack traces follow on indented lines.
+func appendConsole(b []byte, e *Entry, color bool) []byte {
+	paint := func(b []byte, c, s string) []byte {
+		if !color {
+			return append(b, s...)
+		}
+
+		return append(append(append(b, c...), s...), ansiReset...)
+	}
+
+	t := e.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	b = paint(b, ansiFaint, t.Format("15:04:05.000"))
+	b = append(b, ' ')
+
+	sev := e.Severity.String()
+	b = paint(b, severityColor(e.Severity), sev)
+	b = append(b, strings.Repeat(" ", severityWidth-len(sev)+1)...)
+	b = append(b, e.Message...)
+
+	var (
+		pairs     []Field
+		multiline []Field
+	)
+
+	keys := make([]string, 0, len(e.Labels))
+	for k := range e.Labels {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		pairs = append(pairs, String(k, e.Labels[k]))
+	}
+
+	if e.Trace != "" {
+		pairs = append(pairs, String("trace", e.Trace))
+	}
+
+	if e.Source != nil {
+		pairs = append(pairs, String("source", filepath.Base(e.Source.File)+":"+strconv.Itoa(e.Source.Line)))
+	}
+
+	for _, f := range e.Fields {
+		if s, ok := f.Value.(string); ok && strings.Contains(s, "\n") {
+			multiline = append(multiline, f)
+		} else {
+			pairs = append(pairs, f)
+		}
+	}
+
+	for i, f := range pairs {
+		if i == 0 {
+			b = append(b, ' ')
+		}
+
+		b = append(b, ' ')
+		b = paint(b, ansiFaint, f.Key+"=")
+		b = append(b, consoleValue(f.Value)...)
+	}
+
+	b = append(b, '\n')
+
+	for _, f := range multiline {
+		b = paint(b, ansiFaint, "    "+f.Key+":")
+		b = append(b, '\n')
+
+		for _, line := range strings.Split(strings.TrimRight(f.Value.(string), "\n"), "\n") { //nolint:forcetypeassert
+			b = append(append(append(b, "    "...), line...), '\n')
+		}
+	}
+
+	return b
+}
+
+// consoleValue returns a string as is unless it needs quoting, and other values in JSON.
+func consoleValue(v any) string {
+	s, ok := v.(string)
+	if !ok {
+		return string(marshalValue(v))
+	}
+
+	if s == "" || strings.ContainsAny(s, " \t\"=") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
@@ -0,0 +1,32 @@
+package log_test
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestFormat_Console(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf), log.WithErrorOutput(&buf), log.WithFormat(log.Format_Console))
+
+	l.Warningf("disk %d%% used", 90)
+	l.Log(&log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_ERROR,
+		Message:  "failed",
+		Labels:   map[string]string{"user_agent": "grpc-go/1.0 test", "grpc_method": "/a"},
+		Fields:   []log.Field{log.Int("attempt", 2), log.String("stack_trace", "main.main()\n\tmain.go:1")},
+	})
+
+	want := regexp.MustCompile(`^\d\d:\d\d:\d\d\.\d{3} WARNING   disk 90% used\n` +
+		`\d\d:\d\d:\d\d\.\d{3} ERROR     failed  grpc_method=/a user_agent="grpc-go/1.0 test" attempt=2\n` +
+		`    stack_trace:\n    main.main\(\)\n    \tmain.go:1\n$`)
+	if got := buf.String(); !want.MatchString(got) {
+		t.Errorf("unexpected console output:\n%s", got)
+	}
+}
@@ -0,0 +1,249 @@
+package log
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+const maxDedupKeys = 10000
+
+type dedupOption struct {
+	key func(*Entry) string
+	now func() time.Time
+}
+
+type DedupOption func(*dedupOption)
+
+// WithDedupKey sets the function deciding which entries are identical.
+// The default key is the severity, the message and the labels.
+func WithDedupKey(f func(*Entry) string) DedupOption {
+	return func(o *dedupOption) {
+		o.key = f
+	}
+}
+
+func withDedupClock(now func() time.Time) DedupOption {
+	return func(o *dedupOption) {
+		o.now = now
+	}
+}
+
+// Deduplicator collapses identical entries within a window. The first entry is written as is,
+// and the repeats are written as one summary entry at the end of the window with the fields
+// repeat_count (the number of repeats after the first entry), first_seen and last_seen,
+// and stamped with the time it is written.
+// Unlike Sampler, entries of ERROR and above are deduplicated too, except those of the Fatal functions.
+// At most 10000 keys are tracked at a time. While all of them are in their window,
+// entries of other keys are written without being tracked.
+type Deduplicator struct {
+	window time.Duration
+	key    func(*Entry) string
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]*dedupRecord
+	purgeAt time.Time // when the oldest record expires, if pending is full
+}
+
+type dedupRecord struct {
+	entry *Entry
+	emit  func(*Entry)
+	first time.Time
+	last  time.Time
+	count int
+	timer *time.Timer
+}
+
+func NewDeduplicator(window time.Duration, opts ...DedupOption) *Deduplicator {
+	opt := dedupOption{
+		key: defaultDedupKey,
+		now: time.Now,
+	}
+
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	//nolint:exhaustruct
+	return &Deduplicator{
+		window:  window,
+		key:     opt.key,
+		now:     opt.now,
+		pending: make(map[string]*dedupRecord),
+	}
+}
+
+func defaultDedupKey(e *Entry) string {
+	key := e.Severity.String() + "\x00" + e.Message
+
+	keys := make([]string, 0, len(e.Labels))
+	for k := range e.Labels {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		key += "\x00" + k + "=" + e.Labels[k]
+	}
+
+	return key
+}
+
+// Deduplicate reports whether e should be written. If not, e is counted as a repeat,
+// and its summary is passed to emit at the end of the window.
+func (d *Deduplicator) Deduplicate(e *Entry, emit func(*Entry)) bool {
+	key := d.key(e)
+	now := d.now()
+
+	d.mu.Lock()
+
+	rec, ok := d.pending[key]
+	if !ok || now.Sub(rec.first) >= d.window {
+		if !ok && len(d.pending) >= maxDedupKeys {
+			if !now.Before(d.purgeAt) {
+				d.purge(now)
+			}
+
+			if len(d.pending) >= maxDedupKeys {
+				d.mu.Unlock()
+				return true
+			}
+		}
+
+		//nolint:exhaustruct
+		d.pending[key] = &dedupRecord{
+			entry: cloneEntry(e),
+			emit:  emit,
+			first: now,
+			last:  now,
+		}
+
+		d.mu.Unlock()
+
+		if ok && rec.count > 0 { // the timer has not fired yet
+			rec.timer.Stop()
+			rec.emit(rec.summary())
+		}
+
+		return true
+	}
+
+	rec.count++
+	rec.last = now
+
+	if rec.timer == nil {
+		rec.timer = time.AfterFunc(rec.first.Add(d.window).Sub(now), func() {
+			d.expire(key, rec)
+		})
+	}
+
+	d.mu.Unlock()
+
+	return false
+}
+
+// Flush writes the summaries of the pending repeats now, e.g. before exiting.
+func (d *Deduplicator) Flush() {
+	d.mu.Lock()
+
+	var recs []*dedupRecord
+
+	for k, rec := range d.pending {
+		if rec.count > 0 {
+			rec.timer.Stop()
+			recs = append(recs, rec)
+			delete(d.pending, k)
+		}
+	}
+
+	d.mu.Unlock()
+
+	slices.SortFunc(recs, func(a, b *dedupRecord) int {
+		return a.first.Compare(b.first)
+	})
+
+	for _, rec := range recs {
+		rec.emit(rec.summary())
+	}
+}
+
+func (d *Deduplicator) expire(key string, rec *dedupRecord) {
+	d.mu.Lock()
+
+	if d.pending[key] != rec { // already flushed or replaced
+		d.mu.Unlock()
+		return
+	}
+
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	rec.emit(rec.summary())
+}
+
+// purge removes the records without repeats whose window has passed, and sets purgeAt to when
+// the oldest of the others expires. Records with repeats are removed by their timers. d.mu must be held.
+func (d *Deduplicator) purge(now time.Time) {
+	var oldest time.Time
+
+	for k, rec := range d.pending {
+		switch {
+		case rec.count == 0 && now.Sub(rec.first) >= d.window:
+			delete(d.pending, k)
+		case oldest.IsZero() || rec.first.Before(oldest):
+			oldest = rec.first
+		}
+	}
+
+	d.purgeAt = oldest.Add(d.window)
+}
+
+// summary returns the entry with the repeats. Its time is cleared to be stamped when it is written.
+func (rec *dedupRecord) summary() *Entry {
+	e := cloneEntry(rec.entry)
+	e.Time = time.Time{}
+	e.Fields = append(e.Fields,
+		Int("repeat_count", rec.count),
+		Time("first_seen", rec.first),
+		Time("last_seen", rec.last),
+	)
+
+	return e
+}
+
+func cloneEntry(e *Entry) *Entry {
+	c := *e
+	c.Labels = maps.Clone(e.Labels)
+	c.Fields = slices.Clone(e.Fields)
+
+	return &c
+}
+
+// WithDeduplication collapses identical entries within a window. See Deduplicator.
+func WithDeduplication(d *Deduplicator) Option {
+	return func(l *Logger) {
+		l.SetDeduplicator(d)
+	}
+}
+
+// SetDeduplicator replaces the Deduplicator. nil disables deduplication.
+// Pending summaries of the previous Deduplicator are written by its timers.
+func (l *Logger) SetDeduplicator(d *Deduplicator) {
+	l.dedup.Store(d)
+}
+
+func (l *Logger) deduplicate(e *Entry) bool {
+	d := l.dedup.Load()
+	if d == nil {
+		return true
+	}
+
+	return d.Deduplicate(e, func(summary *Entry) {
+		l.stamp(summary, calldepth)
+		l.writeSinks(summary)
+		l.emit(summary, calldepth)
+	})
+}
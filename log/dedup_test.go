@@ -0,0 +1,185 @@
+package log_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+	"github.com/taomics/go-pkg/log/logtest"
+)
+
+func TestDeduplicator(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	d := log.NewDeduplicator(time.Minute, log.WithDedupClock(clock.now))
+	l, rec := logtest.NewLogger(log.WithDeduplication(d))
+
+	for range 1000 {
+		l.Errorln("failed to publish message")
+		clock.advance(10 * time.Millisecond)
+	}
+
+	l.Errorln("another error")
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d", len(entries))
+	}
+
+	rec.AssertCount(t, 2, logtest.MessageContains("failed to publish"))
+
+	summary := entries[2]
+	if summary.Message != "failed to publish message" {
+		t.Fatalf("want summary last, got %q", summary.Message)
+	}
+
+	want := map[string]any{
+		"repeat_count": 999,
+		"first_seen":   "2024-05-01T00:00:00Z",
+		"last_seen":    "2024-05-01T00:00:09.99Z",
+	}
+
+	for k, v := range want {
+		rec.AssertLogged(t, logtest.Field(k, v))
+	}
+}
+
+func TestDeduplicator_window(t *testing.T) {
+	t.Parallel()
+
+	d := log.NewDeduplicator(20 * time.Millisecond)
+	l, rec := logtest.NewLogger(log.WithDeduplication(d))
+
+	l.Warningln("slow")
+	l.Warningln("slow")
+	l.Warningln("slow")
+
+	deadline := time.Now().Add(time.Second)
+	for len(rec.Entries()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rec.AssertLogged(t, logtest.Field("repeat_count", 2))
+
+	l.Warningln("slow")
+	rec.AssertCount(t, 3, logtest.MessageContains("slow"))
+}
+
+func TestDeduplicator_text(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf), log.WithDeduplication(log.NewDeduplicator(time.Minute)))
+	l.SetFlag(0)
+
+	l.Warningln("slow")
+	l.Warningln("slow")
+	l.Warningln("slow")
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 lines, got %q", lines)
+	}
+
+	if s := lines[1]; strings.HasPrefix(s, "{") || !strings.Contains(s, "slow repeat_count=2 first_seen=") {
+		t.Errorf("want a text summary, got %q", s)
+	}
+}
+
+func TestDeduplicator_summaryTime(t *testing.T) {
+	t.Parallel()
+
+	l, rec := logtest.NewLogger(log.WithTimestamp(true), log.WithDeduplication(log.NewDeduplicator(time.Minute)))
+
+	first := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for range 2 {
+		l.Log(&log.Entry{Message: "slow", Time: first}) //nolint:exhaustruct
+	}
+
+	before := time.Now()
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(entries))
+	}
+
+	if got := entries[1].Time; got.Before(before) {
+		t.Errorf("want the summary stamped when written, got %v", got)
+	}
+}
+
+func TestDeduplicator_fatal(t *testing.T) {
+	log.SetExitFunc(func(int) {})
+	defer log.SetExitFunc(nil)
+
+	var buf bytes.Buffer
+
+	l := log.New(
+		log.WithOutput(&buf),
+		log.WithErrorOutput(&buf),
+		log.WithStructuredLogging(true),
+		log.WithDeduplication(log.NewDeduplicator(time.Minute)),
+	)
+
+	l.Errorln("db down")
+	l.Fatalln("db down")
+
+	want := strings.Repeat(`{"severity":"ERROR","message":"db down"}`+"\n", 2)
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestDeduplicator_maxKeys(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	d := log.NewDeduplicator(time.Hour, log.WithDedupClock(clock.now))
+
+	dedup := func(msg string) bool {
+		return d.Deduplicate(&log.Entry{Message: msg}, func(*log.Entry) {}) //nolint:exhaustruct
+	}
+
+	for i := range 10100 {
+		if !dedup("msg " + strconv.Itoa(i)) {
+			t.Fatalf("want the first entry of msg %d", i)
+		}
+	}
+
+	if got := log.DedupKeys(d); got != 10000 {
+		t.Errorf("want 10000 keys, got %d", got)
+	}
+
+	if !dedup("msg 10050") || !dedup("msg 10050") {
+		t.Error("want an untracked key written")
+	}
+
+	clock.advance(time.Hour)
+
+	if !dedup("fresh") || dedup("fresh") {
+		t.Error("want a new key tracked after the window")
+	}
+
+	if got := log.DedupKeys(d); got != 1 {
+		t.Errorf("want the expired keys purged, got %d keys", got)
+	}
+
+	d.Flush()
+}
@@ -0,0 +1,237 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by ConfigureFromEnv.
+const (
+	EnvFormat      = "LOG_FORMAT"       // json (Google Cloud), azure, console or text
+	EnvLevel       = "LOG_LEVEL"        // a severity such as debug or WARNING
+	EnvSampling    = "LOG_SAMPLING"     // <first>:<thereafter>:<interval> such as 100:10:1s, or off
+	EnvSamplingKey = "LOG_SAMPLING_KEY" // the label grouping LOG_SAMPLING by, grpc_method by default
+	EnvRedact      = "LOG_REDACT"       // on, off, or comma separated rules: bearer,jwt,email,card,ip,phone
+	EnvLabels      = "LOG_LABELS"       // comma separated key=value pairs added to every entry
+	EnvTime        = "LOG_TIME"         // true to add the time to every entry
+	EnvSource      = "LOG_SOURCE"       // true to add the source location to every entry
+)
+
+const defaultSamplingKey = "grpc_method"
+
+// serviceEnvs are the environment variables set by the platforms, in order of precedence.
+var serviceEnvs = []struct {
+	label string
+	keys  []string
+}{
+	{"service", []string{"CONTAINER_APP_NAME", "K_SERVICE"}},       // Azure Container Apps, Cloud Run
+	{"revision", []string{"CONTAINER_APP_REVISION", "K_REVISION"}}, // Azure Container Apps, Cloud Run
+	{"replica", []string{"CONTAINER_APP_REPLICA_NAME"}},            // Azure Container Apps
+}
+
+var redactRules = map[string]RedactRule{
+	"bearer": RedactBearerToken,
+	"jwt":    RedactJWT,
+	"email":  RedactEmail,
+	"card":   RedactCreditCard,
+	"ip":     RedactIPAddress,
+	"phone":  RedactPhoneNumber,
+}
+
+// ConfigureFromEnv configures the Default Logger from the environment variables.
+// See Logger.ConfigureFromEnv.
+func ConfigureFromEnv() error {
+	return Default().ConfigureFromEnv()
+}
+
+// ConfigureFromEnv configures l from LOG_FORMAT, LOG_LEVEL, LOG_SAMPLING, LOG_REDACT, LOG_TIME, LOG_SOURCE and LOG_LABELS,
+// so that all services behave consistently. Unset variables leave the settings unchanged.
+// LOG_SAMPLING samples entries by SampleByLabel of LOG_SAMPLING_KEY, or grpc_method if it is unset,
+// so that each RPC method of grpcutil.LogUnaryInterceptors is sampled on its own.
+// The labels service, revision and replica are added from the variables of Azure Container Apps
+// (CONTAINER_APP_NAME, ...) or Cloud Run (K_SERVICE, ...) if they are set.
+// Invalid values are reported together after the valid ones are applied.
+func (l *Logger) ConfigureFromEnv() error {
+	var errs []error
+
+	if v := os.Getenv(EnvFormat); v != "" {
+		errs = append(errs, l.configureFormat(v))
+	}
+
+	if v := os.Getenv(EnvLevel); v != "" {
+		s, err := ParseSeverity(v)
+		if err == nil {
+			l.SetLevel(s)
+		}
+
+		errs = append(errs, envError(EnvLevel, err))
+	}
+
+	if v := os.Getenv(EnvSampling); v != "" {
+		s, err := parseSampling(v, os.Getenv(EnvSamplingKey))
+		if err == nil {
+			l.SetSampler(s)
+		}
+
+		errs = append(errs, envError(EnvSampling, err))
+	}
+
+	if v := os.Getenv(EnvRedact); v != "" {
+		rules, err := parseRedact(v)
+		if err == nil {
+			l.SetRedaction(rules...)
+		}
+
+		errs = append(errs, envError(EnvRedact, err))
+	}
+
+	if v := os.Getenv(EnvTime); v != "" {
+		enable, err := strconv.ParseBool(v)
+		if err == nil {
+			l.EnableTimestamp(enable)
+		}
+
+		errs = append(errs, envError(EnvTime, err))
+	}
+
+	if v := os.Getenv(EnvSource); v != "" {
+		enable, err := strconv.ParseBool(v)
+		if err == nil {
+			l.EnableSourceLocation(enable)
+		}
+
+		errs = append(errs, envError(EnvSource, err))
+	}
+
+	labels, err := envLabels(os.Getenv(EnvLabels))
+	if len(labels) > 0 {
+		merged := l.Labels()
+		if merged == nil {
+			merged = make(map[string]string, len(labels))
+		}
+
+		maps.Copy(merged, labels)
+		l.SetLabels(merged)
+	}
+
+	errs = append(errs, envError(EnvLabels, err))
+
+	return errors.Join(errs...)
+}
+
+func (l *Logger) configureFormat(v string) error {
+	switch strings.ToLower(v) {
+	case "json", "gcp", "google":
+		l.SetFormat(Format_GoogleCloud)
+		l.EnableStructuredLogging(true)
+	case "azure":
+		l.SetFormat(Format_AzureMonitor)
+		l.EnableStructuredLogging(true)
+	case "console":
+		l.SetFormat(Format_Console)
+	case "text":
+		l.SetFormat(Format_GoogleCloud)
+		l.EnableStructuredLogging(false)
+	default:
+		return fmt.Errorf("%s: unknown format %q", EnvFormat, v)
+	}
+
+	return nil
+}
+
+func parseSampling(v, key string) (*Sampler, error) {
+	if strings.EqualFold(v, "off") {
+		return nil, nil //nolint:nilnil
+	}
+
+	parts := strings.Split(v, ":")
+	if len(parts) != 3 { //nolint:mnd
+		return nil, fmt.Errorf("want <first>:<thereafter>:<interval>, got %q", v)
+	}
+
+	first, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid first: %w", err)
+	}
+
+	thereafter, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid thereafter: %w", err)
+	}
+
+	interval, err := time.ParseDuration(parts[2])
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid interval: %q", parts[2])
+	}
+
+	if key == "" {
+		key = defaultSamplingKey
+	}
+
+	return NewSampler(interval, first, thereafter, WithSampleKey(SampleByLabel(key))), nil
+}
+
+func parseRedact(v string) ([]RedactRule, error) {
+	switch strings.ToLower(v) {
+	case "on", "true", "1":
+		return DefaultRedactRules(), nil
+	case "off", "false", "0":
+		return nil, nil
+	}
+
+	var rules []RedactRule
+
+	for _, name := range strings.Split(v, ",") {
+		r, ok := redactRules[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// envLabels returns the labels of the platform and LOG_LABELS. LOG_LABELS takes precedence.
+// It returns no labels if LOG_LABELS is invalid.
+func envLabels(v string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for _, se := range serviceEnvs {
+		for _, k := range se.keys {
+			if s := os.Getenv(k); s != "" {
+				labels[se.label] = s
+				break
+			}
+		}
+	}
+
+	if v == "" {
+		return labels, nil
+	}
+
+	for _, kv := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("want key=value, got %q", kv)
+		}
+
+		labels[strings.TrimSpace(k)] = strings.TrimSpace(val)
+	}
+
+	return labels, nil
+}
+
+func envError(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("%s: %w", key, err)
+}
@@ -0,0 +1,86 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestConfigureFromEnv(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "azure")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_SAMPLING", "1:0:1m")
+	t.Setenv("LOG_REDACT", "email, ip")
+	t.Setenv("LOG_LABELS", "team=health,revision=override")
+	t.Setenv("CONTAINER_APP_NAME", "api")
+	t.Setenv("CONTAINER_APP_REVISION", "api--r1")
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf))
+	if err := l.ConfigureFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debugln("login taomics@example.com from 10.1.2.3")
+	l.Debugln("login taomics@example.com from 10.1.2.3") // sampled out
+
+	want := `{"severityLevel":0,"message":"login ta*@example.com from 10.1.2.0",` +
+		`"customDimensions":{"revision":"override","service":"api","team":"health"}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestConfigureFromEnv_invalid(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "xml")
+	t.Setenv("LOG_LEVEL", "warning")
+	t.Setenv("LOG_SAMPLING", "10")
+	t.Setenv("LOG_REDACT", "ssn")
+	t.Setenv("LOG_LABELS", "team=health,broken")
+	t.Setenv("CONTAINER_APP_NAME", "api")
+
+	l := log.New()
+
+	err := l.ConfigureFromEnv()
+	if err == nil {
+		t.Fatal("should return error")
+	}
+
+	for _, key := range []string{"LOG_FORMAT", "LOG_SAMPLING", "LOG_REDACT", "LOG_LABELS"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error should mention %s: %v", key, err)
+		}
+	}
+
+	if l.Level() != log.Severity_WARNING {
+		t.Errorf("valid LOG_LEVEL should be applied: %s", l.Level())
+	}
+
+	if labels := l.Labels(); len(labels) != 0 {
+		t.Errorf("invalid LOG_LABELS should apply no labels: %v", labels)
+	}
+}
+
+func TestConfigureFromEnv_samplingKey(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_SAMPLING", "1:0:1m")
+	t.Setenv("LOG_SAMPLING_KEY", "tenant")
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf))
+	if err := l.ConfigureFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tenant := range []string{"a", "b", "a"} { // the second a is sampled out
+		l.Log(&log.Entry{Message: "quota exceeded", Labels: map[string]string{"tenant": tenant}}) //nolint:exhaustruct
+	}
+
+	if got := strings.Count(buf.String(), "quota exceeded"); got != 2 {
+		t.Errorf("want 2 entries, got %d: %s", got, buf.String())
+	}
+}
@@ -0,0 +1,137 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const (
+	reportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+	maxStackDepth          = 64
+)
+
+// stackError is an error with the stack trace of where WithStack was called.
+type stackError struct {
+	err error
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string { return e.err.Error() }
+func (e *stackError) Unwrap() error { return e.err }
+
+// WithStack annotates err with the stack trace of the caller, which ReportError writes
+// instead of the stack trace of its own caller. It returns err as is if err is nil or already has one.
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var se *stackError
+	if errors.As(err, &se) {
+		return err
+	}
+
+	return &stackError{err: err, pcs: callers(3)} //nolint:mnd
+}
+
+// ReportError writes err as an ERROR entry in the Cloud Error Reporting format
+// (@type ReportedErrorEvent) with the chain of wrapped errors and a stack trace.
+// The stack trace is the one recorded by WithStack if any, otherwise the caller's.
+func (l *Logger) ReportError(err error, fields ...Field) {
+	l.reportError(err, fields)
+}
+
+func (l *Logger) reportError(err error, fields []Field) {
+	if err == nil || !l.Enabled(Severity_ERROR) {
+		return
+	}
+
+	pcs := callers(4) //nolint:mnd
+
+	var se *stackError
+	if errors.As(err, &se) {
+		pcs = se.pcs
+	}
+
+	l.log(newErrorEntry(err, pcs, fields), calldepth)
+}
+
+func newErrorEntry(err error, pcs []uintptr, fields []Field) *Entry {
+	msg := err.Error()
+
+	efs := []Field{
+		{"@type", reportedErrorEventType},
+		{"stack_trace", msg + "\n\n" + formatStack(pcs)},
+		{"error_chain", errorChain(err)},
+	}
+
+	if frames := runtime.CallersFrames(pcs); len(pcs) > 0 {
+		f, _ := frames.Next()
+		efs = append(efs, Object("context", Object("reportLocation",
+			String("filePath", f.File),
+			Int("lineNumber", f.Line),
+			String("functionName", f.Function),
+		)))
+	}
+
+	return &Entry{ //nolint:exhaustruct
+		Severity: Severity_ERROR,
+		Message:  msg,
+		Fields:   append(efs, fields...),
+	}
+}
+
+// errorChain returns the type and message of err and its wrapped errors, depth first.
+func errorChain(err error) []Fields {
+	var chain []Fields
+
+	for err != nil {
+		if _, ok := err.(*stackError); !ok { //nolint:errorlint
+			chain = append(chain, Fields{String("type", fmt.Sprintf("%T", err)), String("message", err.Error())})
+		}
+
+		switch u := err.(type) { //nolint:errorlint
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				chain = append(chain, errorChain(e)...)
+			}
+
+			return chain
+		default:
+			return chain
+		}
+	}
+
+	return chain
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(skip, pcs)]
+}
+
+// formatStack formats pcs like a goroutine trace of a panic, which Error Reporting parses.
+func formatStack(pcs []uintptr) string {
+	var sb strings.Builder
+
+	sb.WriteString("goroutine 1 [running]:\n")
+
+	frames := runtime.CallersFrames(pcs)
+
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			fmt.Fprintf(&sb, "%s(...)\n\t%s:%d +0x%x\n", f.Function, f.File, f.Line, f.PC-f.Entry)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,115 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestReportError(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithErrorOutput(&buf))
+
+	base := &fs.PathError{Op: "open", Path: "/tmp/x", Err: fs.ErrNotExist}
+	err := fmt.Errorf("load config: %w", errors.Join(base, errors.New("fallback failed")))
+	l.ReportError(err, log.String("job", "daily"))
+
+	var got struct {
+		Severity   string `json:"severity"`
+		Message    string `json:"message"`
+		Type       string `json:"@type"`
+		StackTrace string `json:"stack_trace"`
+		ErrorChain []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error_chain"`
+		Context struct {
+			ReportLocation struct {
+				FilePath     string `json:"filePath"`
+				FunctionName string `json:"functionName"`
+			} `json:"reportLocation"`
+		} `json:"context"`
+		Job string `json:"job"`
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %s: %v", buf.String(), err)
+	}
+
+	if got.Severity != "ERROR" || got.Message != err.Error() || got.Job != "daily" {
+		t.Errorf("unexpected entry: %s", buf.String())
+	}
+
+	if got.Type != "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent" {
+		t.Errorf("unexpected @type: %s", got.Type)
+	}
+
+	if !strings.HasPrefix(got.StackTrace, err.Error()+"\n\ngoroutine 1 [running]:\ngithub.com/taomics/go-pkg/log_test.TestReportError(...)\n") {
+		t.Errorf("stack trace should start at the caller: %s", got.StackTrace)
+	}
+
+	if fn := got.Context.ReportLocation.FunctionName; fn != "github.com/taomics/go-pkg/log_test.TestReportError" {
+		t.Errorf("unexpected report location: %s", fn)
+	}
+
+	wantTypes := []string{"*fmt.wrapError", "*errors.joinError", "*fs.PathError", "*errors.errorString", "*errors.errorString"}
+	if len(got.ErrorChain) != len(wantTypes) {
+		t.Fatalf("want %d errors in chain, got %+v", len(wantTypes), got.ErrorChain)
+	}
+
+	for i, w := range wantTypes {
+		if got.ErrorChain[i].Type != w {
+			t.Errorf("chain[%d]: want %s, got %s", i, w, got.ErrorChain[i].Type)
+		}
+	}
+}
+
+func TestWithStack(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithErrorOutput(&buf))
+	err := newStackError()
+
+	if log.WithStack(err) != err {
+		t.Error("WithStack should not wrap twice")
+	}
+
+	l.ReportError(fmt.Errorf("wrapped: %w", err))
+
+	if !strings.Contains(buf.String(), `"functionName":"github.com/taomics/go-pkg/log_test.newStackError"`) {
+		t.Errorf("the stack trace of WithStack should be reported: %s", buf.String())
+	}
+}
+
+func newStackError() error {
+	return log.WithStack(errors.New("origin"))
+}
+
+func TestReportError_redaction(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithErrorOutput(&buf), log.WithRedaction(log.DefaultRedactRules()...))
+	l.ReportError(fmt.Errorf("wrap: %w", errors.New("user alice@example.com not found")), //nolint:err113
+		log.Any("emails", []any{"bob@example.com", []string{"carol@example.com"}}))
+
+	if got := buf.String(); strings.Contains(got, "alice@") || strings.Contains(got, "bob@") || strings.Contains(got, "carol@") {
+		t.Errorf("raw email written: %s", got)
+	}
+
+	if got := buf.String(); strings.Count(got, "a*@example.com") < 4 {
+		t.Errorf("want the masked email in message, stack_trace and error_chain, got %s", got)
+	}
+}
@@ -0,0 +1,154 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const defaultExitTimeout = 5 * time.Second
+
+// ExitHook releases resources before the process exits, e.g. closing a pubsub Publisher
+// or flushing a buffered writer. It should return when ctx is done.
+type ExitHook func(ctx context.Context) error
+
+type exitHook struct {
+	f ExitHook
+}
+
+var (
+	exitMu      sync.Mutex
+	exitHooks   []*exitHook
+	exitTimeout = defaultExitTimeout
+	exitFunc    = os.Exit
+	exitDone    chan struct{} // not nil while exiting
+)
+
+// RegisterExitHook registers f to be run by Exit and the Fatal functions. Hooks run in the reverse
+// order of registration like deferred calls, sharing the deadline set by SetExitTimeout.
+// The returned function unregisters f.
+func RegisterExitHook(f ExitHook) (unregister func()) {
+	h := &exitHook{f}
+
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	exitHooks = append(exitHooks, h)
+
+	return func() {
+		exitMu.Lock()
+		defer exitMu.Unlock()
+
+		exitHooks = slices.DeleteFunc(exitHooks, func(e *exitHook) bool { return e == h })
+	}
+}
+
+// SetExitTimeout sets how long Exit waits for the hooks. The default is 5 seconds.
+func SetExitTimeout(d time.Duration) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	exitTimeout = d
+}
+
+// SetExitFunc replaces os.Exit called by Exit and the Fatal functions, e.g. to record the code in tests.
+// Note that the caller of Fatal continues if f returns. nil restores os.Exit.
+func SetExitFunc(f func(code int)) {
+	if f == nil {
+		f = os.Exit
+	}
+
+	exitMu.Lock()
+	defer exitMu.Unlock()
+
+	exitFunc = f
+}
+
+// Exit runs the exit hooks, flushes the Default Logger and exits with code.
+// Use it instead of os.Exit, which skips deferred calls and loses buffered entries.
+//
+// Only the first call exits. Later calls of Exit and the Fatal functions, from other goroutines
+// or the hooks, flush their Logger and block until the process exits, so a hook calling them
+// delays the exit until the timeout set by SetExitTimeout.
+func Exit(code int) {
+	exit(code, Default())
+}
+
+func exit(code int, l *Logger) {
+	exitMu.Lock()
+
+	if done := exitDone; done != nil {
+		exitMu.Unlock()
+
+		flushOnExit(l)
+		<-done
+
+		return
+	}
+
+	done := make(chan struct{})
+	exitDone = done
+	hooks := slices.Clone(exitHooks)
+	timeout := exitTimeout
+	f := exitFunc
+	exitMu.Unlock()
+
+	defer func() { // for exit functions which return in tests
+		exitMu.Lock()
+		exitDone = nil
+		exitMu.Unlock()
+
+		close(done)
+	}()
+
+	runExitHooks(hooks, timeout)
+	flushOnExit(l)
+
+	if d := Default(); d != l {
+		flushOnExit(d)
+	}
+
+	f(code)
+}
+
+func runExitHooks(hooks []*exitHook, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := runExitHook(ctx, hooks[i].f); err != nil {
+				fmt.Fprintf(os.Stderr, "go-pkg/log: exit hook: %v\n", err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		fmt.Fprintf(os.Stderr, "go-pkg/log: exit hooks did not finish in %s\n", timeout)
+	}
+}
+
+func runExitHook(ctx context.Context, f ExitHook) (err error) { //nolint:nonamedreturns
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return f(ctx)
+}
+
+func flushOnExit(l *Logger) {
+	if err := l.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "go-pkg/log: flush: %v\n", err)
+	}
+}
@@ -0,0 +1,150 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestFatal_exitHooks(t *testing.T) {
+	var (
+		code  = -1
+		order []string
+		buf   bytes.Buffer
+	)
+
+	log.SetExitFunc(func(c int) { code = c })
+	defer log.SetExitFunc(nil)
+
+	aw := log.NewAsyncWriter(&buf)
+	l := log.New(log.WithOutput(aw), log.WithErrorOutput(aw), log.WithStructuredLogging(true))
+
+	unregister := log.RegisterExitHook(func(context.Context) error {
+		order = append(order, "first")
+		return nil
+	})
+	defer unregister()
+
+	removed := log.RegisterExitHook(func(context.Context) error {
+		order = append(order, "removed")
+		return nil
+	})
+	removed()
+
+	unregister = log.RegisterExitHook(func(context.Context) error {
+		order = append(order, "second")
+		l.Println("closing") // written before the flush
+
+		return nil
+	})
+	defer unregister()
+
+	l.Fatalf("cannot start: %s", "port in use")
+
+	if code != 1 {
+		t.Errorf("want exit code 1, got %d", code)
+	}
+
+	if got := strings.Join(order, ","); got != "second,first" {
+		t.Errorf("want hooks second,first, got %s", got)
+	}
+
+	want := `{"severity":"ERROR","message":"cannot start: port in use"}` + "\n" +
+		`{"severity":"INFO","message":"closing"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestExit_timeout(t *testing.T) {
+	code := -1
+
+	log.SetExitFunc(func(c int) { code = c })
+	defer log.SetExitFunc(nil)
+
+	log.SetExitTimeout(10 * time.Millisecond)
+	defer log.SetExitTimeout(5 * time.Second)
+
+	unregister := log.RegisterExitHook(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(time.Second) // ignores the deadline
+
+		return nil
+	})
+	defer unregister()
+
+	start := time.Now()
+	log.Exit(2)
+
+	if code != 2 {
+		t.Errorf("want exit code 2, got %d", code)
+	}
+
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("want Exit to give up the hook after the timeout, took %s", d)
+	}
+}
+
+func TestExit_concurrent(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		codes []int
+	)
+
+	log.SetExitFunc(func(c int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		codes = append(codes, c)
+	})
+	defer log.SetExitFunc(nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	unregister := log.RegisterExitHook(func(context.Context) error {
+		close(started)
+		<-release
+
+		return nil
+	})
+	defer unregister()
+
+	first := make(chan struct{})
+
+	go func() {
+		defer close(first)
+
+		log.Exit(1)
+	}()
+
+	<-started
+
+	second := make(chan struct{})
+
+	go func() {
+		defer close(second)
+
+		log.Exit(2)
+	}()
+
+	select {
+	case <-second:
+		t.Fatal("want the second Exit to block while the first one runs the hooks")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-first
+	<-second
+
+	if !slices.Equal(codes, []int{1}) {
+		t.Errorf("want only the first exit code 1, got %v", codes)
+	}
+}
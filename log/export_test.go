@@ -0,0 +1,30 @@
+package log
+
+var (
+	WithSamplerClock = withSamplerClock
+	WithRotateClock  = withRotateClock
+	WithDedupClock   = withDedupClock
+)
+
+// WithTerminal makes l select Format_Console as the Default Logger does on a terminal.
+func WithTerminal() Option {
+	return func(l *Logger) {
+		l.selectConsole()
+	}
+}
+
+// SampleKeys returns the number of keys counted by s.
+func SampleKeys(s *Sampler) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.counters)
+}
+
+// DedupKeys returns the number of keys tracked by d.
+func DedupKeys(d *Deduplicator) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.pending)
+}
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Field is a typed value written natively in the JSON payload of a structured entry,
+// unlike Labels which are strings indexed by the logging backend.
+// Use labels for low-cardinality values to filter by, and fields for everything else.
+type Field struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Field          { return Field{key, value} }
+func Int(key string, value int) Field         { return Field{key, value} }
+func Int64(key string, value int64) Field     { return Field{key, value} }
+func Float64(key string, value float64) Field { return Field{key, value} }
+func Bool(key string, value bool) Field       { return Field{key, value} }
+
+// Duration is written as a string such as "1.5s", the JSON representation of google.protobuf.Duration.
+func Duration(key string, value time.Duration) Field {
+	return Field{key, strconv.FormatFloat(value.Seconds(), 'f', -1, 64) + "s"}
+}
+
+// Time is written in RFC 3339 with nanoseconds.
+func Time(key string, value time.Time) Field {
+	return Field{key, value.Format(time.RFC3339Nano)}
+}
+
+// Err is written as the error message with the key "error". A nil error is written as null.
+func Err(err error) Field {
+	if err == nil {
+		return Field{"error", nil}
+	}
+
+	return Field{"error", err.Error()}
+}
+
+// Any is written as encoding/json marshals value. If it fails, value is written as a %+v string.
+func Any(key string, value any) Field {
+	return Field{key, value}
+}
+
+// Object is written as a nested JSON object of fields in order.
+func Object(key string, fields ...Field) Field {
+	return Field{key, Fields(fields)}
+}
+
+// Fields is marshaled as a JSON object keeping the order of fields.
+type Fields []Field
+
+func (fs Fields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	fs.appendMembers(&buf, nil)
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// appendMembers writes `"key":value` pairs separated by commas.
+// Keys in reserved are prefixed with "field_" not to overwrite them.
+func (fs Fields) appendMembers(buf *bytes.Buffer, reserved map[string]bool) {
+	for i, f := range fs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key := f.Key
+		if reserved[key] {
+			key = "field_" + key
+		}
+
+		k, _ := json.Marshal(key) //nolint:errchkjson
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(marshalValue(f.Value))
+	}
+}
+
+func marshalValue(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%+v", v)) //nolint:errchkjson
+	}
+
+	return b
+}
+
+// spliceFields appends fields as members of the JSON object b.
+func spliceFields(b []byte, fields Fields, reserved map[string]bool) []byte {
+	if len(fields) == 0 {
+		return b
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(b[:len(b)-1]) // without '}'
+
+	if len(b) > len("{}") {
+		buf.WriteByte(',')
+	}
+
+	fields.appendMembers(&buf, reserved)
+	buf.WriteByte('}')
+
+	return buf.Bytes()
+}
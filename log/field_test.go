@@ -0,0 +1,63 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestEntry_Fields(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf), log.WithRedaction(log.RedactEmail))
+	l.Log(&log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_INFO,
+		Message:  "done",
+		Labels:   map[string]string{"method": "Get"},
+		Fields: []log.Field{
+			log.Int("count", 3),
+			log.Duration("latency", 1500*time.Millisecond),
+			log.Bool("cached", true),
+			log.Err(errors.New("not found")),
+			log.Object("user", log.String("email", "taomics@example.com"), log.Int64("id", 42)),
+			log.Any("tags", []string{"a", "b"}),
+			log.Any("ch", make(chan int)),
+			log.String("message", "reserved"),
+		},
+	})
+
+	want := `{"severity":"INFO","message":"done","labels":{"method":"Get"},` +
+		`"count":3,"latency":"1.5s","cached":true,"error":"not found",` +
+		`"user":{"email":"ta*@example.com","id":42},"tags":["a","b"],"ch":"` // channel is written as %+v
+	if got := buf.String(); len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("want prefix %s, got %s", want, got)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"field_message":"reserved"}`)) {
+		t.Errorf("reserved key should be renamed: %s", buf.String())
+	}
+}
+
+func TestEntry_FieldsAzure(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf), log.WithFormat(log.Format_AzureMonitor))
+	l.Log(&log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_INFO,
+		Message:  "done",
+		Labels:   map[string]string{"method": "Get"},
+		Fields:   []log.Field{log.Int("count", 3)},
+	})
+
+	want := `{"severityLevel":1,"message":"done","customDimensions":{"method":"Get","count":3}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,146 @@
+package log
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Format selects the schema of structured (JSON) log entries, or the console format.
+type Format int32
+
+const (
+	// Format_GoogleCloud writes entries as Google Cloud Logging structured logs.
+	Format_GoogleCloud Format = iota
+
+	// Format_AzureMonitor writes entries in the Application Insights trace schema
+	// (severityLevel, operation_Id, customDimensions) so that Log Analytics can
+	// query them without post-processing.
+	Format_AzureMonitor
+
+	// Format_Console writes colored, aligned lines with labels and fields for local development.
+	// It is used for both Log and the print functions regardless of EnableStructuredLogging,
+	// and is selected for the Default Logger when os.Stderr is a terminal.
+	Format_Console
+)
+
+// SetFormat changes the schema used by structured logging, or selects Format_Console.
+func SetFormat(f Format) {
+	Default().SetFormat(f)
+}
+
+// Application Insights SeverityLevel values.
+// https://learn.microsoft.com/en-us/azure/azure-monitor/app/data-model-complete#severitylevel
+const (
+	azureSeverityVerbose     = 0
+	azureSeverityInformation = 1
+	azureSeverityWarning     = 2
+	azureSeverityError       = 3
+	azureSeverityCritical    = 4
+)
+
+type azureEntry struct {
+	Time              string `json:"time,omitempty"`
+	SeverityLevel     int    `json:"severityLevel"`
+	Message           string `json:"message,omitempty"`
+	OperationID       string `json:"operation_Id,omitempty"`
+	OperationParentID string `json:"operation_ParentId,omitempty"`
+	CustomDimensions  Fields `json:"customDimensions,omitempty"`
+}
+
+func newAzureEntry(e *Entry) *azureEntry {
+	ae := &azureEntry{
+		Time:              "",
+		SeverityLevel:     azureSeverityLevel(e.Severity),
+		Message:           e.Message,
+		OperationID:       traceID(e.Trace),
+		OperationParentID: e.SpanID,
+		CustomDimensions:  customDimensions(e),
+	}
+
+	if !e.Time.IsZero() {
+		ae.Time = e.Time.Format(time.RFC3339Nano)
+	}
+
+	return ae
+}
+
+// traceID returns the trace ID of the Cloud Logging form projects/<project>/traces/<id>, or trace as is.
+func traceID(trace string) string {
+	if i := strings.LastIndex(trace, "/traces/"); i >= 0 {
+		return trace[i+len("/traces/"):]
+	}
+
+	return trace
+}
+
+// customDimensions returns labels in key order followed by fields, and the source location
+// as code.filepath, code.lineno and code.function of the OpenTelemetry semantic conventions.
+func customDimensions(e *Entry) Fields {
+	keys := make([]string, 0, len(e.Labels))
+	for k := range e.Labels {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	dims := make(Fields, 0, len(e.Labels)+len(e.Fields))
+	for _, k := range keys {
+		dims = append(dims, String(k, e.Labels[k]))
+	}
+
+	dims = append(dims, e.Fields...)
+
+	return append(dims, sourceFields(e.Source)...)
+}
+
+func sourceFields(src *SourceLocation) Fields {
+	if src == nil {
+		return nil
+	}
+
+	return Fields{
+		String("code.filepath", src.File),
+		Int("code.lineno", src.Line),
+		String("code.function", src.Function),
+	}
+}
+
+func azureSeverityLevel(s Severity) int {
+	switch {
+	case s >= Severity_CRITICAL:
+		return azureSeverityCritical
+	case s >= Severity_ERROR:
+		return azureSeverityError
+	case s >= Severity_WARNING:
+		return azureSeverityWarning
+	case s >= Severity_INFO:
+		return azureSeverityInformation
+	default:
+		return azureSeverityVerbose
+	}
+}
+
+// encodable returns the value to be JSON encoded for e in the format.
+func (f Format) encodable(e *Entry) any {
+	if f == Format_AzureMonitor {
+		return newAzureEntry(e)
+	}
+
+	return e
+}
+
+// appendEntry renders e as one line of JSON in f, or as console lines for Format_Console.
+func (f Format) appendEntry(b []byte, e *Entry, color bool) ([]byte, error) {
+	if f == Format_Console {
+		return appendConsole(b, e, color), nil
+	}
+
+	j, err := json.Marshal(f.encodable(e))
+	if err != nil {
+		return b, err //nolint:wrapcheck
+	}
+
+	return append(append(b, j...), '\n'), nil
+}
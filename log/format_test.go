@@ -0,0 +1,87 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestFormat_AzureMonitor(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetOutput(&buf)
+	log.SetErrorOutput(&buf)
+	log.SetFormat(log.Format_AzureMonitor)
+
+	defer func() {
+		log.SetFormat(log.Format_GoogleCloud)
+	}()
+
+	log.Log(&log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_WARNING,
+		Message:  "hello",
+		Labels:   map[string]string{"grpc_method": "/test.Service/Get"},
+		Trace:    "4bf92f3577b34da6a3ce929d0e0e4736",
+	})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+
+	if v := got["severityLevel"]; v != float64(2) {
+		t.Errorf("severityLevel: want 2, got %v", v)
+	}
+
+	if v := got["message"]; v != "hello" {
+		t.Errorf("message: want hello, got %v", v)
+	}
+
+	if v := got["operation_Id"]; v != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("operation_Id: want trace id, got %v", v)
+	}
+
+	dims, ok := got["customDimensions"].(map[string]any)
+	if !ok || dims["grpc_method"] != "/test.Service/Get" {
+		t.Errorf("customDimensions: want labels, got %v", got["customDimensions"])
+	}
+}
+
+func TestFormat_AzureMonitor_traceResourceName(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf), log.WithStructuredLogging(true), log.WithFormat(log.Format_AzureMonitor))
+	l.Log(&log.Entry{Message: "hello", Trace: "projects/p/traces/4bf92f3577b34da6a3ce929d0e0e4736"}) //nolint:exhaustruct
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+
+	if v := got["operation_Id"]; v != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("operation_Id: want the bare trace id, got %v", v)
+	}
+}
+
+func TestFormat_GoogleCloud(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetOutput(&buf)
+	log.SetErrorOutput(&buf)
+
+	log.Log(&log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_ERROR,
+		Message:  "failed",
+		Labels:   nil,
+		Trace:    "projects/p/traces/abc",
+	})
+
+	want := `{"severity":"ERROR","message":"failed","logging.googleapis.com/trace":"projects/p/traces/abc"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
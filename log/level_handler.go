@@ -0,0 +1,189 @@
+package log
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultLoggerName = "default"
+
+var registry sync.Map // name -> *Logger
+
+// WithName registers the Logger by name so that LevelHandler can change its level,
+// e.g. one Logger per package. The Default Logger is registered as "default".
+func WithName(name string) Option {
+	return func(l *Logger) {
+		registry.Store(name, l)
+	}
+}
+
+// Lookup returns the Logger registered by WithName.
+func Lookup(name string) (*Logger, bool) {
+	if name == "" || name == defaultLoggerName {
+		return Default(), true
+	}
+
+	v, ok := registry.Load(name)
+	if !ok {
+		return nil, false
+	}
+
+	return v.(*Logger), true //nolint:forcetypeassert
+}
+
+// SetLevelFor changes the minimum severity for d, and then reverts it to the level before
+// the first temporary change. d <= 0 changes the level permanently like SetLevel.
+func (l *Logger) SetLevelFor(s Severity, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev := l.Level()
+	if l.revert != nil {
+		l.revert.Stop()
+		prev = l.revertTo
+		l.revert = nil
+		l.revertAt = time.Time{}
+	}
+
+	l.level.Store(int64(s))
+
+	if d <= 0 {
+		return
+	}
+
+	var t *time.Timer
+
+	t = time.AfterFunc(d, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.revert == t { // not replaced by another change
+			l.level.Store(int64(prev))
+			l.revert = nil
+			l.revertAt = time.Time{}
+		}
+	})
+
+	l.revert = t
+	l.revertTo = prev
+	l.revertAt = time.Now().Add(d)
+}
+
+type levelState struct {
+	Logger   string     `json:"logger"`
+	Level    Severity   `json:"level"`
+	RevertTo *Severity  `json:"revert_to,omitempty"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+func (l *Logger) levelState(name string) levelState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	st := levelState{Logger: name, Level: l.Level(), RevertTo: nil, RevertAt: nil}
+
+	if l.revert != nil {
+		to, at := l.revertTo, l.revertAt
+		st.RevertTo, st.RevertAt = &to, &at
+	}
+
+	return st
+}
+
+// LevelHandler reports and changes the minimum severity of the registered Loggers at runtime.
+//
+//	GET  ?logger=name                           reports the levels of all or the named Logger
+//	POST ?logger=name&level=debug&duration=10m  changes the level, reverting it after duration if given
+//
+// logger defaults to the Default Logger. Parameters may also be sent as a form.
+// The handler should be served only on an internal port or behind authentication.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			serveLevels(w, r.URL.Query().Get("logger"))
+		case http.MethodPost, http.MethodPut:
+			changeLevel(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func serveLevels(w http.ResponseWriter, name string) {
+	if name != "" {
+		l, ok := Lookup(name)
+		if !ok {
+			http.Error(w, "no such logger: "+name, http.StatusNotFound)
+			return
+		}
+
+		writeJSON(w, l.levelState(name))
+
+		return
+	}
+
+	states := []levelState{Default().levelState(defaultLoggerName)}
+
+	registry.Range(func(k, v any) bool {
+		states = append(states, v.(*Logger).levelState(k.(string))) //nolint:forcetypeassert
+		return true
+	})
+
+	slices.SortFunc(states[1:], func(a, b levelState) int {
+		return strings.Compare(a.Logger, b.Logger)
+	})
+
+	writeJSON(w, states)
+}
+
+func changeLevel(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("logger")
+	if name == "" {
+		name = defaultLoggerName
+	}
+
+	l, ok := Lookup(name)
+	if !ok {
+		http.Error(w, "no such logger: "+name, http.StatusNotFound)
+		return
+	}
+
+	s, err := ParseSeverity(r.FormValue("level"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var d time.Duration
+
+	if v := r.FormValue("duration"); v != "" {
+		d, err = time.ParseDuration(v)
+		if err != nil {
+			http.Error(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	l.SetLevelFor(s, d)
+
+	if d > 0 {
+		l.Noticef("go-pkg/log: level of %s logger changed to %s for %s", name, s, d)
+	} else {
+		l.Noticef("go-pkg/log: level of %s logger changed to %s", name, s)
+	}
+
+	writeJSON(w, l.levelState(name))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		Errorf("go-pkg/log: write response: %v", err)
+	}
+}
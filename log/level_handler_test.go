@@ -0,0 +1,98 @@
+package log_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestLogger_SetLevelFor(t *testing.T) {
+	t.Parallel()
+
+	l := log.New(log.WithOutput(io.Discard), log.WithLevel(log.Severity_WARNING))
+
+	l.SetLevelFor(log.Severity_DEBUG, 20*time.Millisecond)
+	l.SetLevelFor(log.Severity_INFO, 20*time.Millisecond) // reverts to WARNING, not DEBUG
+
+	if l.Level() != log.Severity_INFO {
+		t.Errorf("want INFO, got %s", l.Level())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if l.Level() != log.Severity_WARNING {
+		t.Errorf("want WARNING after revert, got %s", l.Level())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Parallel()
+
+	l := log.New(log.WithOutput(io.Discard), log.WithName("level-handler-test"))
+	server := httptest.NewServer(log.LevelHandler())
+
+	defer server.Close()
+
+	form := url.Values{"logger": {"level-handler-test"}, "level": {"debug"}, "duration": {"1h"}}
+
+	res, err := http.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode())) //nolint:noctx
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var st struct {
+		Logger   string     `json:"logger"`
+		Level    string     `json:"level"`
+		RevertTo string     `json:"revert_to"`
+		RevertAt *time.Time `json:"revert_at"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Level != "DEBUG" || st.RevertTo != "INFO" || st.RevertAt == nil {
+		t.Errorf("unexpected state: %+v", st)
+	}
+
+	if l.Level() != log.Severity_DEBUG {
+		t.Errorf("want DEBUG, got %s", l.Level())
+	}
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{"?logger=level-handler-test", http.StatusOK},
+		{"?logger=no-such-logger", http.StatusNotFound},
+	} {
+		res, err := http.Get(server.URL + tt.query) //nolint:noctx
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+
+		if res.StatusCode != tt.want {
+			t.Errorf("%s: want %d, got %d", tt.query, tt.want, res.StatusCode)
+		}
+	}
+
+	res2, err := http.Post(server.URL+"?level=verbose", "", nil) //nolint:noctx
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res2.Body.Close()
+
+	if res2.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid level: want 400, got %d", res2.StatusCode)
+	}
+}
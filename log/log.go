@@ -1,12 +1,13 @@
 package log
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Severity int
@@ -23,201 +24,151 @@ const (
 	Severity_EMERGENCY Severity = 800
 )
 
-//nolint:cyclop
-func (s Severity) MarshalJSON() ([]byte, error) {
-	switch s {
-	default:
-		fallthrough
-	case Severity_DEFAULT:
-		return []byte(`"DEFAULT"`), nil
-	case Severity_DEBUG:
-		return []byte(`"DEBUG"`), nil
-	case Severity_INFO:
-		return []byte(`"INFO"`), nil
-	case Severity_NOTICE:
-		return []byte(`"NOTICE"`), nil
-	case Severity_WARNING:
-		return []byte(`"WARNING"`), nil
-	case Severity_ERROR:
-		return []byte(`"ERROR"`), nil
-	case Severity_CRITICAL:
-		return []byte(`"CRITICAL"`), nil
-	case Severity_ALERT:
-		return []byte(`"ALERT"`), nil
-	case Severity_EMERGENCY:
-		return []byte(`"EMERGENCY"`), nil
-	}
-}
-
 type Entry struct {
 	Severity Severity          `json:"severity"`
 	Message  string            `json:"message,omitempty"`
 	Labels   map[string]string `json:"labels,omitempty"`
-}
 
-func Println(v ...any)               { _Println(v...) }
-func Printf(format string, v ...any) { _Printf(format, v...) }
-func Errorln(v ...any)               { _Errorln(v...) }
-func Errorf(format string, v ...any) { _Errorf(format, v...) }
-func Fatalln(v ...any)               { _Fatalln(v...) }
-func Fatalf(format string, v ...any) { _Fatalf(format, v...) }
-func Log(e *Entry) {
-	if e == nil {
-		return
-	}
+	// Trace and SpanID correlate the entry with a request.
+	// They are written as operation_Id and operation_ParentId in Format_AzureMonitor,
+	// where a Trace of the form projects/<project>/traces/<id> is written as <id>.
+	Trace  string `json:"logging.googleapis.com/trace,omitempty"`
+	SpanID string `json:"logging.googleapis.com/spanId,omitempty"`
 
-	if e.Severity < Severity_ERROR {
-		infolog.JSONEncode(e)
-	} else {
-		errlog.JSONEncode(e)
-	}
+	// Fields are written as members of the entry (jsonPayload in Cloud Logging).
+	Fields []Field `json:"-"`
+
+	// Time is when the entry was written, in RFC3339Nano. It is set by Loggers with EnableTimestamp.
+	Time time.Time `json:"-"`
+
+	// Source is the code which wrote the entry. It is set by Loggers with EnableSourceLocation.
+	Source *SourceLocation `json:"logging.googleapis.com/sourceLocation,omitempty"`
 }
 
-var (
-	_Println = infoln
-	_Printf  = infof
-	_Errorln = errorln
-	_Errorf  = errorf
-	_Fatalln = fatalln
-	_Fatalf  = fatalf
-)
+// SourceLocation is the location in the source code, in the Cloud Logging LogEntrySourceLocation schema.
+type SourceLocation struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Function string `json:"function,omitempty"`
+}
 
-type logger struct {
-	*log.Logger
-	enc      *json.Encoder
-	severity Severity
+// entryKeys are the JSON keys of Entry. Fields with these keys are renamed.
+var entryKeys = map[string]bool{
+	"severity":                              true,
+	"message":                               true,
+	"labels":                                true,
+	"logging.googleapis.com/trace":          true,
+	"logging.googleapis.com/spanId":         true,
+	"logging.googleapis.com/sourceLocation": true,
+	"time":                                  true,
 }
 
-func (l logger) JSONEncode(e *Entry) {
-	if e == nil {
-		return
-	}
+func (e Entry) MarshalJSON() ([]byte, error) {
+	type entry Entry // without MarshalJSON
 
-	if e.Severity < l.severity {
-		e.Severity = l.severity
+	b, err := json.Marshal(entry(e))
+	if err != nil {
+		return nil, err //nolint:wrapcheck
 	}
 
-	if err := l.enc.Encode(e); err != nil {
-		errorf(`{"severity":"ERROR","message":"%s: %+v"}`, err, e)
+	if !e.Time.IsZero() {
+		b = spliceFields(b, Fields{String("time", e.Time.Format(time.RFC3339Nano))}, nil)
 	}
-}
-
-var (
-	infolog = logger{log.New(os.Stderr, "INFO: ", 0), json.NewEncoder(os.Stderr), Severity_INFO}
-	errlog  = logger{log.New(os.Stderr, "ERROR: ", 0), json.NewEncoder(os.Stderr), Severity_ERROR}
-	mux     sync.Mutex
-)
-
-func SetFlag(flag int) {
-	infolog.SetFlags(flag)
-	errlog.SetFlags(flag)
-}
 
-func SetPrefix(prefix string) {
-	infolog.SetPrefix(prefix)
-	errlog.SetPrefix(prefix)
+	return spliceFields(b, e.Fields, entryKeys), nil
 }
 
-func SetOutput(w io.Writer) {
-	mux.Lock()
-	defer mux.Unlock()
+var defaultLogger atomic.Pointer[Logger]
 
-	infolog.SetOutput(w)
-	infolog.enc = json.NewEncoder(w)
+func init() {
+	defaultLogger.Store(newDefault())
 }
 
-func SetErrorOutput(w io.Writer) {
-	mux.Lock()
-	defer mux.Unlock()
+// newDefault returns New with Format_Console if os.Stderr is a terminal. Console is dropped
+// when an output which is not a terminal, a format or structured logging is set later.
+func newDefault() *Logger {
+	l := New()
 
-	errlog.SetOutput(w)
-	errlog.enc = json.NewEncoder(w)
-}
-
-func EnableStructuredLogging(enable bool) {
-	mux.Lock()
-	defer mux.Unlock()
-
-	//nolint:wsl
-	if enable {
-		_Println = jsonInfoln
-		_Printf = jsonInfof
-		_Errorln = jsonErrorln
-		_Errorf = jsonErrorf
-		_Fatalln = jsonFatalln
-		_Fatalf = jsonFatalf
-		infolog.SetFlags(0)
-		infolog.SetPrefix("")
-		errlog.SetFlags(0)
-		errlog.SetPrefix("")
-	} else {
-		_Println = infoln
-		_Printf = infof
-		_Errorln = errorln
-		_Errorf = errorf
-		_Fatalln = fatalln
-		_Fatalf = fatalf
+	if isTerminal(os.Stderr) {
+		l.selectConsole()
 	}
-}
 
-func infoln(v ...any) {
-	infolog.Println(v...)
+	return l
 }
 
-func infof(format string, v ...any) {
-	infolog.Printf(format, v...)
+// selectConsole selects Format_Console with colors until dropAutoFormat.
+func (l *Logger) selectConsole() {
+	l.colorOut.Store(true)
+	l.colorErr.Store(true)
+	l.format.Store(int32(Format_Console))
+	l.autoFormat.Store(true)
 }
 
-func errorln(v ...any) {
-	errlog.Println(v...)
+// Default returns the Logger used by the package-level functions. It is New, but uses Format_Console
+// while os.Stderr is a terminal and no other output, format or structured logging is set.
+func Default() *Logger {
+	return defaultLogger.Load()
 }
 
-func fatalln(v ...any) {
-	if err := errlog.Output(3, fmt.Sprintln(v...)); err != nil { //nolint:mnd
-		log.Println(err.Error())
-		log.Println(v...)
+// SetDefault replaces the Logger used by the package-level functions.
+func SetDefault(l *Logger) {
+	if l == nil {
+		l = newDefault()
 	}
 
-	os.Exit(1)
+	defaultLogger.Store(l)
 }
 
-func fatalf(format string, v ...any) {
-	if err := errlog.Output(3, fmt.Sprintf(format, v...)); err != nil { //nolint:mnd
-		log.Println(err.Error())
-		log.Printf(format, v...)
-	}
+func Debugln(v ...any)                   { Default().logln(Severity_DEBUG, v...) }
+func Debugf(format string, v ...any)     { Default().logf(Severity_DEBUG, format, v...) }
+func Println(v ...any)                   { Default().logln(Severity_INFO, v...) }
+func Printf(format string, v ...any)     { Default().logf(Severity_INFO, format, v...) }
+func Noticeln(v ...any)                  { Default().logln(Severity_NOTICE, v...) }
+func Noticef(format string, v ...any)    { Default().logf(Severity_NOTICE, format, v...) }
+func Warningln(v ...any)                 { Default().logln(Severity_WARNING, v...) }
+func Warningf(format string, v ...any)   { Default().logf(Severity_WARNING, format, v...) }
+func Errorln(v ...any)                   { Default().logln(Severity_ERROR, v...) }
+func Errorf(format string, v ...any)     { Default().logf(Severity_ERROR, format, v...) }
+func Criticalln(v ...any)                { Default().logln(Severity_CRITICAL, v...) }
+func Criticalf(format string, v ...any)  { Default().logf(Severity_CRITICAL, format, v...) }
+func Alertln(v ...any)                   { Default().logln(Severity_ALERT, v...) }
+func Alertf(format string, v ...any)     { Default().logf(Severity_ALERT, format, v...) }
+func Emergencyln(v ...any)               { Default().logln(Severity_EMERGENCY, v...) }
+func Emergencyf(format string, v ...any) { Default().logf(Severity_EMERGENCY, format, v...) }
 
-	os.Exit(1)
-}
+// Fatalln and Fatalf write an ERROR entry regardless of Level, and exit with 1 like Exit.
+func Fatalln(v ...any)               { Default().fatal(sprintln(v...)) }
+func Fatalf(format string, v ...any) { Default().fatal(fmt.Sprintf(format, v...)) }
 
-func errorf(format string, v ...any) {
-	errlog.Printf(format, v...)
-}
+// Log writes e as a structured entry. Severity_DEFAULT is written as INFO.
+func Log(e *Entry) { Default().log(e, calldepth-1) }
 
-func jsonInfoln(v ...any) {
-	s := fmt.Sprintln(v...)
-	infolog.JSONEncode(&Entry{Message: s[:len(s)-1]}) //nolint:exhaustruct
-}
+// LogContext writes e like Log, correlating it with the trace in ctx. See Logger.LogContext.
+func LogContext(ctx context.Context, e *Entry) { Default().logContext(ctx, e) }
 
-func jsonInfof(format string, v ...any) {
-	infolog.JSONEncode(&Entry{Message: fmt.Sprintf(format, v...)}) //nolint:exhaustruct
-}
+// ReportError writes err in the Cloud Error Reporting format. See Logger.ReportError.
+func ReportError(err error, fields ...Field) { Default().reportError(err, fields) }
 
-func jsonErrorln(v ...any) {
-	s := fmt.Sprintln(v...)
-	errlog.JSONEncode(&Entry{Message: s[:len(s)-1]}) //nolint:exhaustruct
-}
+func SetFlag(flag int)               { Default().SetFlag(flag) }
+func SetPrefix(prefix string)        { Default().SetPrefix(prefix) }
+func SetOutput(w io.Writer)          { Default().SetOutput(w) }
+func SetErrorOutput(w io.Writer)     { Default().SetErrorOutput(w) }
+func EnableStructuredLogging(b bool) { Default().EnableStructuredLogging(b) }
 
-func jsonErrorf(format string, v ...any) {
-	errlog.JSONEncode(&Entry{Message: fmt.Sprintf(format, v...)}) //nolint:exhaustruct
-}
+// SetRedaction replaces the redaction rules of the Default Logger. See Logger.SetRedaction.
+func SetRedaction(rules ...RedactRule) { Default().SetRedaction(rules...) }
 
-func jsonFatalln(v ...any) {
-	jsonErrorln(v...)
-	os.Exit(1)
-}
+// SetSampler replaces the Sampler of the Default Logger. nil disables sampling.
+func SetSampler(s *Sampler) { Default().SetSampler(s) }
 
-func jsonFatalf(format string, v ...any) {
-	jsonErrorf(format, v...)
-	os.Exit(1)
-}
+// EnableTimestamp adds the time to the entries of the Default Logger. See Logger.EnableTimestamp.
+func EnableTimestamp(enable bool) { Default().EnableTimestamp(enable) }
+
+// EnableSourceLocation adds the caller to the entries of the Default Logger. See Logger.EnableSourceLocation.
+func EnableSourceLocation(enable bool) { Default().EnableSourceLocation(enable) }
+
+// SetDeduplicator replaces the Deduplicator of the Default Logger. nil disables deduplication.
+func SetDeduplicator(d *Deduplicator) { Default().SetDeduplicator(d) }
+
+// Flush flushes the outputs of the Default Logger. See Logger.Flush.
+func Flush() error { return Default().Flush() }
@@ -0,0 +1,102 @@
+package log_test
+
+import (
+	"bytes"
+	stdlog "log"
+	"strings"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestLeveledLogging(t *testing.T) {
+	var out, errOut bytes.Buffer
+
+	log.SetOutput(&out)
+	log.SetErrorOutput(&errOut)
+	log.EnableStructuredLogging(true)
+	log.SetLevel(log.Severity_NOTICE)
+
+	defer log.SetLevel(log.Severity_INFO)
+
+	log.Debugln("debug")
+	log.Printf("info %d", 1)
+	log.Noticef("notice %d", 2)
+	log.Warningln("warning", 3)
+	log.Criticalf("critical %d", 4)
+
+	wantOut := `{"severity":"NOTICE","message":"notice 2"}` + "\n" +
+		`{"severity":"WARNING","message":"warning 3"}` + "\n"
+	if got := out.String(); got != wantOut {
+		t.Errorf("want %s, got %s", wantOut, got)
+	}
+
+	wantErr := `{"severity":"CRITICAL","message":"critical 4"}` + "\n"
+	if got := errOut.String(); got != wantErr {
+		t.Errorf("want %s, got %s", wantErr, got)
+	}
+}
+
+func TestTextLogging(t *testing.T) {
+	var buf bytes.Buffer
+
+	log.SetOutput(&buf)
+	log.EnableStructuredLogging(false)
+	log.SetPrefix("")
+	log.SetFlag(stdlog.Lshortfile)
+
+	defer func() {
+		log.SetFlag(0)
+		log.EnableStructuredLogging(true)
+	}()
+
+	log.Warningf("warn %s", "text")
+
+	if got := buf.String(); !strings.HasPrefix(got, "log_test.go:") || !strings.HasSuffix(got, ": warn text\n") {
+		t.Errorf("caller of Warningf should be reported: %q", got)
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    log.Severity
+		wantErr bool
+	}{
+		{"debug", log.Severity_DEBUG, false},
+		{"WARN", log.Severity_WARNING, false},
+		{" Emergency ", log.Severity_EMERGENCY, false},
+		{"500", log.Severity_ERROR, false},
+		{" 300 ", log.Severity_NOTICE, false},
+		{"150", log.Severity_DEFAULT, true},
+		{"verbose", log.Severity_DEFAULT, true},
+		{"900", log.Severity_DEFAULT, true},
+	}
+
+	for _, tt := range tests {
+		got, err := log.ParseSeverity(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("%q: want %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestSetLevelFromEnv(t *testing.T) {
+	t.Setenv("TEST_LOG_LEVEL", "debug")
+
+	defer log.SetLevel(log.Severity_INFO)
+
+	if err := log.SetLevelFromEnv("TEST_LOG_LEVEL"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !log.Enabled(log.Severity_DEBUG) {
+		t.Errorf("DEBUG should be enabled, level=%s", log.Level())
+	}
+}
@@ -0,0 +1,547 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Logger writes entries to its own outputs with its own format, level and default labels.
+// The package-level functions use the Default Logger.
+type Logger struct {
+	mu           sync.Mutex // guards out and errOut, and serializes writes so that entries never interleave
+	out          io.Writer
+	errOut       io.Writer
+	colorOut     atomic.Bool // out is a terminal
+	colorErr     atomic.Bool // errOut is a terminal
+	loggers      []*logger   // ordered by severity. Each severity has its own prefix in the text mode.
+	structured   atomic.Bool
+	format       atomic.Int32
+	level        atomic.Int64
+	revert       *time.Timer // reverts a temporary level set by SetLevelFor. guarded by mu
+	revertTo     Severity
+	revertAt     time.Time
+	labels       atomic.Pointer[map[string]string]
+	redactor     atomic.Pointer[Redactor]
+	sampler      atomic.Pointer[Sampler]
+	sinks        atomic.Pointer[[]Sink]
+	sinksOnly    atomic.Bool // entries are not written to out and errOut
+	dedup        atomic.Pointer[Deduplicator]
+	timestamp    atomic.Bool
+	source       atomic.Bool
+	traceFrom    atomic.Pointer[TraceExtractor]
+	traceProject atomic.Pointer[string]
+	autoFormat   atomic.Bool // Format_Console was selected for a terminal, and is dropped by an explicit setting
+}
+
+type Option func(*Logger)
+
+// WithOutput sets the output of entries below ERROR.
+func WithOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.SetOutput(w)
+	}
+}
+
+// WithErrorOutput sets the output of entries of ERROR and above.
+func WithErrorOutput(w io.Writer) Option {
+	return func(l *Logger) {
+		l.SetErrorOutput(w)
+	}
+}
+
+func WithStructuredLogging(enable bool) Option {
+	return func(l *Logger) {
+		l.EnableStructuredLogging(enable)
+	}
+}
+
+func WithFormat(f Format) Option {
+	return func(l *Logger) {
+		l.SetFormat(f)
+	}
+}
+
+func WithTimestamp(enable bool) Option {
+	return func(l *Logger) {
+		l.EnableTimestamp(enable)
+	}
+}
+
+func WithSourceLocation(enable bool) Option {
+	return func(l *Logger) {
+		l.EnableSourceLocation(enable)
+	}
+}
+
+func WithLevel(s Severity) Option {
+	return func(l *Logger) {
+		l.SetLevel(s)
+	}
+}
+
+// WithLabels sets labels added to every structured entry. Labels of an entry take precedence.
+func WithLabels(labels map[string]string) Option {
+	return func(l *Logger) {
+		l.SetLabels(labels)
+	}
+}
+
+// WithRedaction applies rules to every entry. See Redactor for what is redacted.
+func WithRedaction(rules ...RedactRule) Option {
+	return func(l *Logger) {
+		l.SetRedaction(rules...)
+	}
+}
+
+// WithSampler samples entries below ERROR. See Sampler.
+func WithSampler(s *Sampler) Option {
+	return func(l *Logger) {
+		l.SetSampler(s)
+	}
+}
+
+// New returns a Logger writing text to os.Stderr at Severity_INFO unless opts say otherwise.
+//
+//nolint:exhaustruct
+func New(opts ...Option) *Logger {
+	l := &Logger{
+		out:    os.Stderr,
+		errOut: os.Stderr,
+	}
+
+	for _, s := range []Severity{
+		Severity_DEBUG, Severity_INFO, Severity_NOTICE, Severity_WARNING,
+		Severity_ERROR, Severity_CRITICAL, Severity_ALERT, Severity_EMERGENCY,
+	} {
+		l.loggers = append(l.loggers, &logger{log.New(severityWriter{l, s}, s.String()+": ", 0), s})
+	}
+
+	l.level.Store(int64(Severity_INFO))
+
+	for _, f := range opts {
+		f(l)
+	}
+
+	return l
+}
+
+func (l *Logger) Debugln(v ...any)                   { l.logln(Severity_DEBUG, v...) }
+func (l *Logger) Debugf(format string, v ...any)     { l.logf(Severity_DEBUG, format, v...) }
+func (l *Logger) Println(v ...any)                   { l.logln(Severity_INFO, v...) }
+func (l *Logger) Printf(format string, v ...any)     { l.logf(Severity_INFO, format, v...) }
+func (l *Logger) Noticeln(v ...any)                  { l.logln(Severity_NOTICE, v...) }
+func (l *Logger) Noticef(format string, v ...any)    { l.logf(Severity_NOTICE, format, v...) }
+func (l *Logger) Warningln(v ...any)                 { l.logln(Severity_WARNING, v...) }
+func (l *Logger) Warningf(format string, v ...any)   { l.logf(Severity_WARNING, format, v...) }
+func (l *Logger) Errorln(v ...any)                   { l.logln(Severity_ERROR, v...) }
+func (l *Logger) Errorf(format string, v ...any)     { l.logf(Severity_ERROR, format, v...) }
+func (l *Logger) Criticalln(v ...any)                { l.logln(Severity_CRITICAL, v...) }
+func (l *Logger) Criticalf(format string, v ...any)  { l.logf(Severity_CRITICAL, format, v...) }
+func (l *Logger) Alertln(v ...any)                   { l.logln(Severity_ALERT, v...) }
+func (l *Logger) Alertf(format string, v ...any)     { l.logf(Severity_ALERT, format, v...) }
+func (l *Logger) Emergencyln(v ...any)               { l.logln(Severity_EMERGENCY, v...) }
+func (l *Logger) Emergencyf(format string, v ...any) { l.logf(Severity_EMERGENCY, format, v...) }
+
+// Fatalln and Fatalf write an ERROR entry regardless of Level, and exit with 1 like Exit.
+func (l *Logger) Fatalln(v ...any)               { l.fatal(sprintln(v...)) }
+func (l *Logger) Fatalf(format string, v ...any) { l.fatal(fmt.Sprintf(format, v...)) }
+
+// Log writes e as a structured entry. Severity_DEFAULT is written as INFO.
+// e is not modified, so it can be reused.
+func (l *Logger) Log(e *Entry) {
+	l.log(e, calldepth-1)
+}
+
+// log writes a copy of e, so that the caller can reuse e. depth is the number of frames from stamp
+// to the caller as in calldepth.
+func (l *Logger) log(e *Entry, depth int) {
+	if e == nil {
+		return
+	}
+
+	c := *e
+	e = &c
+
+	if e.Severity == Severity_DEFAULT {
+		e.Severity = Severity_INFO
+	}
+
+	if !l.Enabled(e.Severity) || !l.sample(e) {
+		return
+	}
+
+	l.stamp(e, depth)
+	e = l.prepare(e)
+	if !l.deduplicate(e) {
+		return
+	}
+
+	l.writeSinks(e)
+	l.encode(e)
+}
+
+func (l *Logger) SetFlag(flag int) {
+	for _, il := range l.loggers {
+		il.SetFlags(flag)
+	}
+}
+
+func (l *Logger) SetPrefix(prefix string) {
+	for _, il := range l.loggers {
+		il.SetPrefix(prefix)
+	}
+}
+
+// SetOutput sets the output of entries below ERROR.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.out = w
+	l.colorOut.Store(isTerminal(w))
+
+	if !l.colorOut.Load() {
+		l.dropAutoFormat()
+	}
+}
+
+// SetErrorOutput sets the output of entries of ERROR and above.
+func (l *Logger) SetErrorOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.errOut = w
+	l.colorErr.Store(isTerminal(w))
+
+	if !l.colorErr.Load() {
+		l.dropAutoFormat()
+	}
+}
+
+// Flush writes the pending summaries of the Deduplicator, and flushes the outputs and sinks
+// which buffer entries such as AsyncWriter.
+// Exit and the Fatal functions call it before exiting.
+func (l *Logger) Flush() error {
+	if d := l.dedup.Load(); d != nil {
+		d.Flush()
+	}
+
+	l.mu.Lock()
+	outputs := []io.Writer{l.out}
+
+	if l.errOut != l.out {
+		outputs = append(outputs, l.errOut)
+	}
+	l.mu.Unlock()
+
+	var errs []error
+
+	for _, w := range outputs {
+		if f, ok := w.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if p := l.sinks.Load(); p != nil {
+		for _, s := range *p {
+			if f, ok := s.(flusher); ok {
+				if err := f.Flush(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type flusher interface {
+	Flush() error
+}
+
+func (l *Logger) EnableStructuredLogging(enable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.structured.Store(enable)
+
+	if enable {
+		l.dropAutoFormat()
+
+		for _, il := range l.loggers {
+			il.SetFlags(0)
+			il.SetPrefix("")
+		}
+	}
+}
+
+// SetFormat changes the schema used by structured logging.
+func (l *Logger) SetFormat(f Format) {
+	l.autoFormat.Store(false)
+	l.format.Store(int32(f))
+}
+
+// dropAutoFormat restores the default format if Format_Console was selected only for a terminal.
+func (l *Logger) dropAutoFormat() {
+	if l.autoFormat.CompareAndSwap(true, false) {
+		l.format.Store(int32(Format_GoogleCloud))
+	}
+}
+
+func (l *Logger) Format() Format {
+	return Format(l.format.Load())
+}
+
+// EnableTimestamp adds the time to every entry, as "time" in RFC3339Nano in JSON.
+// Cloud Logging and Azure Monitor use it instead of the time they received the entry,
+// which keeps the order of entries written in quick succession.
+func (l *Logger) EnableTimestamp(enable bool) {
+	l.timestamp.Store(enable)
+}
+
+// EnableSourceLocation adds the file, line and function of the caller to every entry,
+// as "logging.googleapis.com/sourceLocation" in JSON. It costs a runtime.Caller per entry.
+func (l *Logger) EnableSourceLocation(enable bool) {
+	l.source.Store(enable)
+}
+
+// SetRedaction replaces the redaction rules. No rules disable redaction. See Redactor for what is redacted.
+// DefaultRedactRules covers emails, IP addresses, JWTs, bearer tokens, credit card and phone numbers.
+func (l *Logger) SetRedaction(rules ...RedactRule) {
+	if len(rules) == 0 {
+		l.redactor.Store(nil)
+		return
+	}
+
+	l.redactor.Store(NewRedactor(rules...))
+}
+
+// SetLabels replaces the labels added to every structured entry. Labels of an entry take precedence.
+func (l *Logger) SetLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		l.labels.Store(nil)
+		return
+	}
+
+	labels = maps.Clone(labels)
+	l.labels.Store(&labels)
+}
+
+// Labels returns a copy of the labels added to every structured entry.
+func (l *Logger) Labels() map[string]string {
+	if p := l.labels.Load(); p != nil {
+		return maps.Clone(*p)
+	}
+
+	return nil
+}
+
+// SetSampler replaces the Sampler. nil disables sampling.
+func (l *Logger) SetSampler(s *Sampler) {
+	l.sampler.Store(s)
+}
+
+// SetLevel changes the minimum severity to be written. It is safe to call at runtime,
+// and cancels the revert of SetLevelFor.
+func (l *Logger) SetLevel(s Severity) {
+	l.SetLevelFor(s, 0)
+}
+
+// Level returns the minimum severity to be written.
+func (l *Logger) Level() Severity {
+	return Severity(l.level.Load())
+}
+
+// Enabled reports whether entries of severity s are written.
+func (l *Logger) Enabled(s Severity) bool {
+	return s >= l.Level()
+}
+
+func (l *Logger) logln(s Severity, v ...any) {
+	if !l.Enabled(s) {
+		return
+	}
+
+	l.output(s, sprintln(v...), false)
+}
+
+func (l *Logger) logf(s Severity, format string, v ...any) {
+	if !l.Enabled(s) {
+		return
+	}
+
+	l.output(s, fmt.Sprintf(format, v...), false)
+}
+
+// fatal writes msg bypassing the Sampler and the Deduplicator, so that the reason of the exit
+// is never dropped or folded into a summary, and exits.
+func (l *Logger) fatal(msg string) {
+	l.output(Severity_ERROR, msg, true)
+	exit(1, l)
+}
+
+// calldepth is the number of frames from logger.Output and stamp to the caller of the Logger methods
+// and the package functions.
+const calldepth = 4
+
+// output writes msg at s. Unless always is true, the Sampler and the Deduplicator may drop it.
+func (l *Logger) output(s Severity, msg string, always bool) {
+	e := &Entry{Severity: s, Message: msg} //nolint:exhaustruct
+	if !always && !l.sample(e) {
+		return
+	}
+
+	l.stamp(e, calldepth)
+
+	e = l.prepare(e)
+	if !always && !l.deduplicate(e) {
+		return
+	}
+
+	l.writeSinks(e)
+	l.emit(e, calldepth+1)
+}
+
+// emit writes e encoded in structured, console and sinks only modes, and otherwise as a text line
+// of the message followed by the fields as key=value. depth is the calldepth of the text line.
+func (l *Logger) emit(e *Entry, depth int) {
+	if l.structured.Load() || l.Format() == Format_Console || l.sinksOnly.Load() {
+		l.encode(e)
+		return
+	}
+
+	msg := e.Message
+	for _, f := range e.Fields {
+		msg += " " + f.Key + "=" + consoleValue(f.Value)
+	}
+
+	if err := l.loggerFor(e.Severity).Output(depth, msg); err != nil {
+		log.Println(err.Error())
+		log.Println(msg)
+	}
+}
+
+// stamp sets the time and the source location of e if enabled and not set yet.
+func (l *Logger) stamp(e *Entry, depth int) {
+	if e.Time.IsZero() && l.timestamp.Load() {
+		e.Time = time.Now()
+	}
+
+	if e.Source == nil && l.source.Load() {
+		if pc, file, line, ok := runtime.Caller(depth); ok {
+			fn := ""
+			if f := runtime.FuncForPC(pc); f != nil {
+				fn = f.Name()
+			}
+
+			e.Source = &SourceLocation{File: file, Line: line, Function: fn}
+		}
+	}
+}
+
+func (l *Logger) loggerFor(s Severity) *logger {
+	for i := len(l.loggers) - 1; i > 0; i-- {
+		if s >= l.loggers[i].severity {
+			return l.loggers[i]
+		}
+	}
+
+	return l.loggers[0]
+}
+
+func (l *Logger) sample(e *Entry) bool {
+	if s := l.sampler.Load(); s != nil {
+		return s.Sample(e)
+	}
+
+	return true
+}
+
+// prepare returns e with the default labels, the trace project and redaction applied.
+// e itself is not modified.
+func (l *Logger) prepare(e *Entry) *Entry {
+	e = l.qualifyTrace(e)
+
+	if p := l.labels.Load(); p != nil {
+		labels := maps.Clone(*p)
+		maps.Copy(labels, e.Labels)
+
+		ne := *e
+		ne.Labels = labels
+		e = &ne
+	}
+
+	if r := l.redactor.Load(); r != nil {
+		e = r.redactEntry(e)
+	}
+
+	return e
+}
+
+// encode writes e as one line of JSON, or console lines, with a single Write.
+func (l *Logger) encode(e *Entry) {
+	if l.sinksOnly.Load() {
+		return
+	}
+
+	color := l.colorOut.Load()
+	if e.Severity >= Severity_ERROR {
+		color = l.colorErr.Load()
+	}
+
+	b, err := l.Format().appendEntry(nil, e, color)
+	if err != nil {
+		_, _ = l.write(Severity_ERROR, fmt.Appendf(nil, `{"severity":"ERROR","message":"%s: %+v"}`+"\n", err, e))
+		return
+	}
+
+	if _, err := l.write(e.Severity, b); err != nil {
+		log.Println(err.Error())
+		log.Println(string(b))
+	}
+}
+
+// write writes p to the output of severity s while holding the lock,
+// so that concurrent entries and SetOutput never interleave.
+func (l *Logger) write(s Severity, p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := l.out
+	if s >= Severity_ERROR {
+		w = l.errOut
+	}
+
+	return w.Write(p) //nolint:wrapcheck
+}
+
+// logger formats the text mode. Its output is a severityWriter of the parent Logger.
+type logger struct {
+	*log.Logger
+	severity Severity
+}
+
+type severityWriter struct {
+	l *Logger
+	s Severity
+}
+
+func (w severityWriter) Write(p []byte) (int, error) {
+	return w.l.write(w.s, p)
+}
+
+// sprintln is fmt.Sprintln without the trailing newline.
+func sprintln(v ...any) string {
+	s := fmt.Sprintln(v...)
+	return s[:len(s)-1]
+}
@@ -0,0 +1,154 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestLogger_independentOutputs(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"a", "b", "c"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+
+			l := log.New(log.WithOutput(&buf), log.WithStructuredLogging(true))
+			l.Println(name)
+
+			want := `{"severity":"INFO","message":"` + name + `"}` + "\n"
+			if got := buf.String(); got != want {
+				t.Errorf("want %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestLogger_terminalFormat(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	tests := []struct {
+		name string
+		opts []log.Option
+		want log.Format
+	}{
+		{"terminal", nil, log.Format_Console},
+		{"text", []log.Option{log.WithStructuredLogging(false)}, log.Format_Console},
+		{"output", []log.Option{log.WithOutput(&buf)}, log.Format_GoogleCloud},
+		{"error output", []log.Option{log.WithErrorOutput(&buf)}, log.Format_GoogleCloud},
+		{"structured", []log.Option{log.WithStructuredLogging(true)}, log.Format_GoogleCloud},
+		{"format", []log.Option{log.WithFormat(log.Format_AzureMonitor)}, log.Format_AzureMonitor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			l := log.New(append([]log.Option{log.WithTerminal()}, tt.opts...)...)
+			if got := l.Format(); got != tt.want {
+				t.Errorf("want format %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLogger_defaultLabels(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(
+		log.WithErrorOutput(&buf),
+		log.WithStructuredLogging(true),
+		log.WithLabels(map[string]string{"service": "api", "revision": "r1"}),
+	)
+
+	labels := map[string]string{"revision": "r2"}
+	l.Log(&log.Entry{Severity: log.Severity_ERROR, Message: "failed", Labels: labels}) //nolint:exhaustruct
+
+	want := `{"severity":"ERROR","message":"failed","labels":{"revision":"r2","service":"api"}}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	if len(labels) != 1 {
+		t.Errorf("labels of the entry should not be modified: %v", labels)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	var buf bytes.Buffer
+
+	orig := log.Default()
+	defer log.SetDefault(orig)
+
+	log.SetDefault(log.New(log.WithOutput(&buf), log.WithStructuredLogging(true), log.WithLevel(log.Severity_DEBUG)))
+	log.Debugf("debug %d", 1)
+
+	want := `{"severity":"DEBUG","message":"debug 1"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+// lineWriter is not goroutine safe on purpose. The race detector reports if Logger writes concurrently.
+type lineWriter struct {
+	writes [][]byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func TestLogger_concurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	var (
+		w1, w2 lineWriter
+		wg     sync.WaitGroup
+	)
+
+	l := log.New(log.WithOutput(&w1), log.WithErrorOutput(&w1), log.WithStructuredLogging(true))
+
+	const goroutines, lines = 8, 200
+
+	for i := range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range lines {
+				switch {
+				case i == 0 && j%50 == 0:
+					l.SetOutput(&w2)
+					l.SetErrorOutput(&w2)
+				case j%2 == 0:
+					l.Errorf("goroutine %d line %d %s", i, j, strings.Repeat("x", 4096))
+				default:
+					l.Log(&log.Entry{Severity: log.Severity_INFO, Message: "entry", Labels: map[string]string{"j": strconv.Itoa(j)}}) //nolint:exhaustruct
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, w := range [][][]byte{w1.writes, w2.writes} {
+		for _, b := range w {
+			if !json.Valid(b) || bytes.Count(b, []byte("\n")) != 1 || b[len(b)-1] != '\n' {
+				t.Fatalf("each write should be one JSON line: %q", b)
+			}
+		}
+	}
+}
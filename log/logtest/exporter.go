@@ -0,0 +1,43 @@
+package logtest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+// InMemoryExporter is a log.OTelExporter keeping the exported records in memory.
+//
+//	exp := new(logtest.InMemoryExporter)
+//	l := log.New(log.WithOnlySinks(log.NewOTelSink(exp)))
+type InMemoryExporter struct {
+	mu      sync.Mutex
+	records []log.OTelRecord
+}
+
+func (e *InMemoryExporter) Export(_ context.Context, records []log.OTelRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.records = append(e.records, records...)
+
+	return nil
+}
+
+// Records returns the exported records in order.
+func (e *InMemoryExporter) Records() []log.OTelRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.records)
+}
+
+// Reset discards the exported records.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.records = nil
+}
@@ -0,0 +1,198 @@
+// Package logtest captures log entries in memory for tests.
+//
+//	l, rec := logtest.NewLogger()
+//	doSomething(l)
+//	rec.AssertLogged(t, logtest.Severity(log.Severity_ERROR), logtest.MessageContains("failed"))
+//
+// Each Recorder is independent, so tests using their own Logger can run in parallel.
+package logtest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+// Recorder is a log.Sink keeping copies of the entries in memory.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []log.Entry
+}
+
+// NewLogger returns a Logger recording every entry of DEBUG and above into the returned Recorder
+// without writing them anywhere. opts are applied after the defaults.
+func NewLogger(opts ...log.Option) (*log.Logger, *Recorder) {
+	rec := new(Recorder)
+
+	l := log.New(append([]log.Option{
+		log.WithLevel(log.Severity_DEBUG),
+		log.WithOnlySinks(rec),
+	}, opts...)...)
+
+	return l, rec
+}
+
+// CaptureDefault replaces the Default Logger with a recording one until the test ends.
+// Tests using it must not run in parallel with tests using the package-level functions.
+func CaptureDefault(tb testing.TB, opts ...log.Option) *Recorder {
+	tb.Helper()
+
+	orig := log.Default()
+	l, rec := NewLogger(opts...)
+
+	log.SetDefault(l)
+	tb.Cleanup(func() { log.SetDefault(orig) })
+
+	return rec
+}
+
+func (r *Recorder) WriteEntry(e *log.Entry) error {
+	ce := *e
+	ce.Labels = maps.Clone(e.Labels)
+	ce.Fields = slices.Clone(e.Fields)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, ce)
+
+	return nil
+}
+
+// Entries returns the recorded entries in order.
+func (r *Recorder) Entries() []log.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.entries)
+}
+
+// Reset discards the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
+
+// Find returns the recorded entries matching all matchers.
+func (r *Recorder) Find(matchers ...Matcher) []log.Entry {
+	var found []log.Entry
+
+	for _, e := range r.Entries() {
+		if matchAll(&e, matchers) {
+			found = append(found, e)
+		}
+	}
+
+	return found
+}
+
+// AssertLogged reports an error if no entry matches all matchers.
+func (r *Recorder) AssertLogged(tb testing.TB, matchers ...Matcher) {
+	tb.Helper()
+
+	if len(r.Find(matchers...)) == 0 {
+		tb.Errorf("no entry matches %s\n%s", describe(matchers), r.dump())
+	}
+}
+
+// AssertNotLogged reports an error if any entry matches all matchers.
+func (r *Recorder) AssertNotLogged(tb testing.TB, matchers ...Matcher) {
+	tb.Helper()
+
+	if found := r.Find(matchers...); len(found) > 0 {
+		tb.Errorf("%d entries match %s\n%s", len(found), describe(matchers), r.dump())
+	}
+}
+
+// AssertCount reports an error unless exactly n entries match all matchers.
+func (r *Recorder) AssertCount(tb testing.TB, n int, matchers ...Matcher) {
+	tb.Helper()
+
+	if found := r.Find(matchers...); len(found) != n {
+		tb.Errorf("want %d entries matching %s, got %d\n%s", n, describe(matchers), len(found), r.dump())
+	}
+}
+
+func (r *Recorder) dump() string {
+	var sb strings.Builder
+
+	sb.WriteString("recorded entries:")
+
+	for _, e := range r.Entries() {
+		fmt.Fprintf(&sb, "\n\t%s %q %v", e.Severity, e.Message, e.Labels)
+	}
+
+	return sb.String()
+}
+
+// Matcher selects entries in Find and the assertions.
+type Matcher struct {
+	desc  string
+	match func(*log.Entry) bool
+}
+
+// Severity matches entries of exactly s.
+func Severity(s log.Severity) Matcher {
+	return Matcher{"severity=" + s.String(), func(e *log.Entry) bool { return e.Severity == s }}
+}
+
+// MinSeverity matches entries of s and above.
+func MinSeverity(s log.Severity) Matcher {
+	return Matcher{"severity>=" + s.String(), func(e *log.Entry) bool { return e.Severity >= s }}
+}
+
+func MessageContains(substr string) Matcher {
+	return Matcher{fmt.Sprintf("message contains %q", substr), func(e *log.Entry) bool {
+		return strings.Contains(e.Message, substr)
+	}}
+}
+
+func Label(key, value string) Matcher {
+	return Matcher{fmt.Sprintf("label %s=%q", key, value), func(e *log.Entry) bool {
+		v, ok := e.Labels[key]
+		return ok && v == value
+	}}
+}
+
+// Field matches entries having a field of key whose value equals value.
+func Field(key string, value any) Matcher {
+	return Matcher{fmt.Sprintf("field %s=%v", key, value), func(e *log.Entry) bool {
+		for _, f := range e.Fields {
+			if f.Key == key && fmt.Sprint(f.Value) == fmt.Sprint(value) {
+				return true
+			}
+		}
+
+		return false
+	}}
+}
+
+func matchAll(e *log.Entry, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.match(e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func describe(matchers []Matcher) string {
+	if len(matchers) == 0 {
+		return "anything"
+	}
+
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = m.desc
+	}
+
+	return strings.Join(descs, ", ")
+}
@@ -0,0 +1,72 @@
+package logtest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+	"github.com/taomics/go-pkg/log/logtest"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			l, rec := logtest.NewLogger(log.WithLabels(map[string]string{"service": name}))
+
+			l.Debugf("start %s", name)
+			l.Log(&log.Entry{ //nolint:exhaustruct
+				Severity: log.Severity_ERROR,
+				Message:  "failed to publish message",
+				Labels:   map[string]string{"topic": "mail-requests"},
+				Fields:   []log.Field{log.Err(errors.New("unavailable"))},
+			})
+
+			rec.AssertCount(t, 2, logtest.Label("service", name))
+			rec.AssertLogged(t, logtest.Severity(log.Severity_DEBUG), logtest.MessageContains(name))
+			rec.AssertLogged(t,
+				logtest.MinSeverity(log.Severity_ERROR),
+				logtest.MessageContains("publish"),
+				logtest.Label("topic", "mail-requests"),
+				logtest.Field("error", "unavailable"),
+			)
+			rec.AssertNotLogged(t, logtest.MinSeverity(log.Severity_CRITICAL))
+
+			rec.Reset()
+			rec.AssertCount(t, 0)
+		})
+	}
+}
+
+func TestRecorder_failure(t *testing.T) {
+	t.Parallel()
+
+	_, rec := logtest.NewLogger()
+
+	ft := &fakeTB{} //nolint:exhaustruct
+	rec.AssertLogged(ft, logtest.MessageContains("never"))
+
+	if !ft.failed {
+		t.Error("AssertLogged should fail")
+	}
+}
+
+func TestCaptureDefault(t *testing.T) {
+	rec := logtest.CaptureDefault(t)
+
+	log.Warningf("disk %d%%", 90)
+
+	rec.AssertLogged(t, logtest.Severity(log.Severity_WARNING), logtest.MessageContains("90%"))
+}
+
+type fakeTB struct {
+	testing.TB
+
+	failed bool
+}
+
+func (tb *fakeTB) Helper()               {}
+func (tb *fakeTB) Errorf(string, ...any) { tb.failed = true }
@@ -2,7 +2,17 @@ package log
 
 import (
 	"net"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	reJPPostalCode = regexp.MustCompile(`^〒?\s*\d{3}-?\d{4}\s*`)
+	reJPPrefecture = regexp.MustCompile(`^(?:東京都|北海道|(?:京都|大阪)府|\p{Han}{2,3}県)`)
+	reDate         = regexp.MustCompile(`^(\d{4})\s*[-/.年]\s*(\d{1,2})\s*[-/.月]\s*(\d{1,2})日?$|^(\d{4})(\d{2})(\d{2})$`)
 )
 
 //nolint:mnd
@@ -46,3 +56,97 @@ func MaskEmail(email string) string {
 
 	return "*@" + parts[1]
 }
+
+// MaskPhoneNumber keeps the last 4 digits and the separators, e.g. "+81 90-1234-5678" to "+** **-****-5678".
+// It returns "" if phone has fewer than 7 digits.
+//
+//nolint:mnd
+func MaskPhoneNumber(phone string) string {
+	digits := 0
+
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+
+	if digits < 7 {
+		return ""
+	}
+
+	b := []byte(phone)
+
+	for i := range b {
+		if b[i] >= '0' && b[i] <= '9' {
+			if digits > 4 {
+				b[i] = '*'
+			}
+
+			digits--
+		}
+	}
+
+	return string(b)
+}
+
+// MaskName keeps the first character of each word, e.g. "John Smith" to "J* S*" and "山田 太郎" to "山* 太*".
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, w := range words {
+		r, _ := utf8.DecodeRuneInString(w)
+		words[i] = string(r) + "*"
+	}
+
+	return strings.Join(words, " ")
+}
+
+// MaskAddress keeps only the region of a postal address: the prefecture of a Japanese address
+// ("東京都*"), or the last comma separated part with the digits masked ("*, USA").
+func MaskAddress(addr string) string {
+	addr = strings.TrimSpace(reJPPostalCode.ReplaceAllString(strings.TrimSpace(addr), ""))
+	if addr == "" {
+		return ""
+	}
+
+	if pref := reJPPrefecture.FindString(addr); pref != "" {
+		return pref + "*"
+	}
+
+	i := strings.LastIndexAny(addr, ",、")
+	if i < 0 {
+		return "*"
+	}
+
+	_, size := utf8.DecodeRuneInString(addr[i:])
+
+	region := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '*'
+		}
+
+		return r
+	}, addr[i+size:])
+
+	return "*," + region
+}
+
+// MaskDateOfBirth keeps only the year, e.g. "1990-04-01", "1990/4/1", "1990年4月1日" or "19900401" to "1990-**-**".
+// It returns "" if dob is not a date.
+func MaskDateOfBirth(dob string) string {
+	m := reDate.FindStringSubmatch(strings.TrimSpace(dob))
+	if m == nil {
+		return ""
+	}
+
+	return m[1] + m[4] + "-**-**" // one of the alternatives is empty
+}
+
+// MaskFreeText replaces free text such as notes and journals entirely, keeping only its length
+// in characters for debugging, e.g. "[REDACTED 42 chars]".
+func MaskFreeText(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return "[REDACTED " + strconv.Itoa(utf8.RuneCountInString(text)) + " chars]"
+}
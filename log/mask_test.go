@@ -0,0 +1,96 @@
+package log_test
+
+import (
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestMasks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		mask func(string) string
+		in   string
+		want string
+	}{
+		{"phone international", log.MaskPhoneNumber, "+81 90-1234-5678", "+** **-****-5678"},
+		{"phone domestic", log.MaskPhoneNumber, "09012345678", "*******5678"},
+		{"phone too short", log.MaskPhoneNumber, "12345", ""},
+		{"name", log.MaskName, "John Smith", "J* S*"},
+		{"name japanese", log.MaskName, "山田 太郎", "山* 太*"},
+		{"name empty", log.MaskName, "", ""},
+		{"address japanese", log.MaskAddress, "〒100-0001 東京都千代田区千代田1-1", "東京都*"},
+		{"address prefecture", log.MaskAddress, "神奈川県横浜市中区1-2-3", "神奈川県*"},
+		{"address western", log.MaskAddress, "1600 Amphitheatre Pkwy, Mountain View, CA 94043", "*, CA *****"},
+		{"address without region", log.MaskAddress, "somewhere", "*"},
+		{"dob iso", log.MaskDateOfBirth, "1990-04-01", "1990-**-**"},
+		{"dob japanese", log.MaskDateOfBirth, "1990年4月1日", "1990-**-**"},
+		{"dob compact", log.MaskDateOfBirth, "19900401", "1990-**-**"},
+		{"dob invalid", log.MaskDateOfBirth, "yesterday", ""},
+		{"free text", log.MaskFreeText, "頭痛がひどい", "[REDACTED 6 chars]"},
+		{"free text empty", log.MaskFreeText, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.mask(tt.in); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+type maskedRecipient struct {
+	UserID string
+	Email  string `log:"mask=email"`
+	Name   string `log:"mask=name"`
+}
+
+type maskedRequest struct {
+	maskedRecipient
+
+	CC      []*maskedRecipient
+	Body    string            `log:"mask=text"`
+	Data    map[string]string `log:"-"`
+	Secret  string            `log:"mask=unknown"`
+	Payload any
+}
+
+func TestMaskStruct(t *testing.T) {
+	t.Parallel()
+
+	req := maskedRequest{
+		maskedRecipient: maskedRecipient{UserID: "u1", Email: "taro.yamada@example.com", Name: "Taro Yamada"},
+		CC:              []*maskedRecipient{{UserID: "u2", Email: "hanako@example.com", Name: "Hanako"}},
+		Body:            "hello",
+		Data:            map[string]string{"name": "Taro"},
+		Secret:          "s3cret",
+		Payload:         maskedRecipient{UserID: "u3", Email: "jiro@example.com", Name: "Jiro"},
+	}
+
+	got := log.MaskStruct(req)
+
+	if got.UserID != "u1" || got.Email != "ta*@example.com" || got.Name != "T* Y*" {
+		t.Errorf("embedded: got %+v", got.maskedRecipient)
+	}
+
+	if cc := got.CC[0]; cc.Email != "ha*@example.com" || cc.Name != "H*" {
+		t.Errorf("slice of pointers: got %+v", cc)
+	}
+
+	if got.Body != "[REDACTED 5 chars]" || got.Data != nil || got.Secret != "" {
+		t.Errorf("tagged: got %q %v %q", got.Body, got.Data, got.Secret)
+	}
+
+	if p, _ := got.Payload.(maskedRecipient); p.Email != "j*@example.com" {
+		t.Errorf("interface: got %+v", got.Payload)
+	}
+
+	if req.Email != "taro.yamada@example.com" || req.CC[0].Email != "hanako@example.com" || req.Data == nil {
+		t.Errorf("original modified: %+v", req)
+	}
+}
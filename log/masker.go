@@ -0,0 +1,162 @@
+package log
+
+import (
+	"reflect"
+	"strings"
+)
+
+const maxMaskDepth = 32
+
+// masks are the masks of the struct tag `log:"mask=<name>"`.
+var masks = map[string]func(string) string{
+	"email":   MaskEmail,
+	"ip":      MaskIPAddress,
+	"phone":   MaskPhoneNumber,
+	"name":    MaskName,
+	"address": MaskAddress,
+	"dob":     MaskDateOfBirth,
+	"text":    MaskFreeText,
+	"redact":  func(string) string { return redacted },
+}
+
+// MaskStruct returns a deep copy of v with the fields tagged for masking masked, so that
+// arbitrary structs can be logged with Any or Object:
+//
+//	type Recipient struct {
+//		UserID string
+//		Email  string `log:"mask=email"`
+//		Name   string `log:"mask=name"`
+//		Notes  string `log:"-"`
+//	}
+//
+//	log.Log(&log.Entry{Message: "sent", Fields: []log.Field{log.Any("to", log.MaskStruct(to))}})
+//
+// The masks are email, ip, phone, name, address, dob, text and redact. "-" clears the field.
+// Tagged fields which are not strings, and fields with an unknown mask, are cleared.
+// Nested structs, pointers, slices, maps and interfaces are masked recursively. v is not modified.
+func MaskStruct[T any](v T) T {
+	rv := reflect.ValueOf(&v).Elem()
+
+	masked, ok := maskValue(rv, 0).Interface().(T)
+	if !ok {
+		var zero T
+		return zero
+	}
+
+	return masked
+}
+
+func maskValue(v reflect.Value, depth int) reflect.Value {
+	if depth > maxMaskDepth {
+		return reflect.Zero(v.Type())
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(maskValue(v.Elem(), depth+1))
+
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(maskValue(v.Elem(), depth+1))
+
+		return c
+	case reflect.Struct:
+		return maskStruct(v, depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			c.Index(i).Set(maskValue(v.Index(i), depth+1))
+		}
+
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			c.Index(i).Set(maskValue(v.Index(i), depth+1))
+		}
+
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			c.SetMapIndex(it.Key(), maskValue(it.Value(), depth+1))
+		}
+
+		return c
+	default:
+		return v
+	}
+}
+
+func maskStruct(v reflect.Value, depth int) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	maskFields(c, depth)
+
+	return c
+}
+
+// maskFields masks the fields of the addressable struct v in place.
+func maskFields(v reflect.Value, depth int) {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		f := v.Field(i)
+
+		if !sf.IsExported() {
+			// The exported fields of an embedded unexported struct are still settable.
+			if sf.Anonymous && f.Kind() == reflect.Struct {
+				maskFields(f, depth+1)
+			}
+
+			continue
+		}
+
+		mask, tagged := maskTag(sf.Tag.Get("log"))
+		if !tagged {
+			f.Set(maskValue(f, depth+1))
+			continue
+		}
+
+		if mask == nil || f.Kind() != reflect.String {
+			f.SetZero()
+			continue
+		}
+
+		f.SetString(mask(f.String()))
+	}
+}
+
+// maskTag returns the mask of the tag value. tagged is false if the field is not masked.
+func maskTag(tag string) (mask func(string) string, tagged bool) {
+	if tag == "-" {
+		return nil, true
+	}
+
+	for _, opt := range strings.Split(tag, ",") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(opt), "mask="); ok {
+			return masks[name], true
+		}
+	}
+
+	return nil, false
+}
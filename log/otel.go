@@ -0,0 +1,335 @@
+package log
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OTelSeverity is the SeverityNumber of the OpenTelemetry log data model.
+type OTelSeverity int
+
+const (
+	OTelSeverity_UNSPECIFIED OTelSeverity = 0
+	OTelSeverity_DEBUG       OTelSeverity = 5
+	OTelSeverity_INFO        OTelSeverity = 9
+	OTelSeverity_INFO2       OTelSeverity = 10
+	OTelSeverity_WARN        OTelSeverity = 13
+	OTelSeverity_ERROR       OTelSeverity = 17
+	OTelSeverity_ERROR2      OTelSeverity = 18
+	OTelSeverity_ERROR3      OTelSeverity = 19
+	OTelSeverity_FATAL       OTelSeverity = 21
+)
+
+// OTelSeverity maps s to the OpenTelemetry SeverityNumber.
+// NOTICE is INFO2, and CRITICAL and ALERT are ERROR2 and ERROR3.
+func (s Severity) OTelSeverity() OTelSeverity {
+	switch {
+	case s >= Severity_EMERGENCY:
+		return OTelSeverity_FATAL
+	case s >= Severity_ALERT:
+		return OTelSeverity_ERROR3
+	case s >= Severity_CRITICAL:
+		return OTelSeverity_ERROR2
+	case s >= Severity_ERROR:
+		return OTelSeverity_ERROR
+	case s >= Severity_WARNING:
+		return OTelSeverity_WARN
+	case s >= Severity_NOTICE:
+		return OTelSeverity_INFO2
+	case s >= Severity_INFO:
+		return OTelSeverity_INFO
+	case s >= Severity_DEBUG:
+		return OTelSeverity_DEBUG
+	default:
+		return OTelSeverity_UNSPECIFIED
+	}
+}
+
+// OTelRecord is an entry in the OpenTelemetry log data model.
+type OTelRecord struct {
+	Timestamp         time.Time
+	ObservedTimestamp time.Time
+	SeverityNumber    OTelSeverity
+	SeverityText      string
+	Body              string
+	Attributes        []Field // the labels in key order, the fields, and code.filepath, code.lineno and code.function
+	TraceID           string  // 32 hex digits
+	SpanID            string  // 16 hex digits
+	Resource          []Field
+}
+
+// OTelExporter sends records to an OpenTelemetry backend, e.g. by wrapping an OTLP exporter.
+type OTelExporter interface {
+	Export(ctx context.Context, records []OTelRecord) error
+}
+
+type otelOption struct {
+	batchSize     int
+	flushInterval time.Duration
+	queueSize     int
+	policy        OverflowPolicy
+	resource      []Field
+	timeout       time.Duration
+}
+
+type OTelOption func(*otelOption)
+
+// WithOTelBatchSize exports records in batches of up to n. The default is 512.
+func WithOTelBatchSize(n int) OTelOption {
+	return func(o *otelOption) {
+		o.batchSize = n
+	}
+}
+
+// WithOTelFlushInterval exports a partial batch when d has passed since the last export.
+// The default is 1 second. 0 disables it, leaving partial batches to Flush.
+func WithOTelFlushInterval(d time.Duration) OTelOption {
+	return func(o *otelOption) {
+		o.flushInterval = d
+	}
+}
+
+// WithOTelQueueSize sets how many records wait for export. The default is 2048.
+func WithOTelQueueSize(n int) OTelOption {
+	return func(o *otelOption) {
+		o.queueSize = n
+	}
+}
+
+// WithOTelOverflowPolicy sets what WriteEntry does when the queue is full.
+// The default is Overflow_Drop, so that a slow backend never stalls logging.
+func WithOTelOverflowPolicy(p OverflowPolicy) OTelOption {
+	return func(o *otelOption) {
+		o.policy = p
+	}
+}
+
+// WithOTelResource sets the resource attributes of the records, e.g. service.name.
+func WithOTelResource(fields ...Field) OTelOption {
+	return func(o *otelOption) {
+		o.resource = fields
+	}
+}
+
+// WithOTelTimeout sets the timeout of each export. The default is 10 seconds.
+func WithOTelTimeout(d time.Duration) OTelOption {
+	return func(o *otelOption) {
+		o.timeout = d
+	}
+}
+
+type otelItem struct {
+	r    OTelRecord
+	done chan struct{} // not nil for flush requests
+}
+
+// OTelSink is a Sink exporting entries as OpenTelemetry log records.
+// The trace is correlated from Entry.Trace and Entry.SpanID, which LogContext sets from a context.
+//
+// Records are queued and exported in batches by a background goroutine, so logging does not
+// wait for the backend. Call Flush or Close before exiting, otherwise queued records are lost.
+type OTelSink struct {
+	exp     OTelExporter
+	opt     otelOption
+	queue   chan otelItem
+	mu      sync.RWMutex // guards closed and sending to queue
+	closed  bool
+	done    chan struct{}
+	dropped atomic.Uint64
+	err     atomic.Pointer[error]
+}
+
+// NewOTelSink starts a goroutine exporting to exp.
+func NewOTelSink(exp OTelExporter, opts ...OTelOption) *OTelSink {
+	opt := otelOption{
+		batchSize:     512,         //nolint:mnd
+		flushInterval: time.Second, //nolint:mnd
+		queueSize:     2048,        //nolint:mnd
+		policy:        Overflow_Drop,
+		resource:      nil,
+		timeout:       10 * time.Second, //nolint:mnd
+	}
+
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	opt.batchSize = max(opt.batchSize, 1)
+
+	//nolint:exhaustruct
+	s := &OTelSink{
+		exp:   exp,
+		opt:   opt,
+		queue: make(chan otelItem, opt.queueSize),
+		done:  make(chan struct{}),
+	}
+
+	go s.run()
+
+	return s
+}
+
+func (s *OTelSink) run() {
+	defer close(s.done)
+
+	var tick <-chan time.Time // nil without a flush interval
+
+	if s.opt.flushInterval > 0 {
+		ticker := time.NewTicker(s.opt.flushInterval)
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	batch := make([]OTelRecord, 0, s.opt.batchSize)
+
+	export := func() {
+		if len(batch) > 0 {
+			s.export(batch)
+			batch = make([]OTelRecord, 0, s.opt.batchSize)
+		}
+	}
+
+	for {
+		select {
+		case item, ok := <-s.queue:
+			switch {
+			case !ok:
+				export()
+				return
+			case item.done != nil:
+				export()
+				close(item.done)
+			default:
+				if batch = append(batch, item.r); len(batch) >= s.opt.batchSize {
+					export()
+				}
+			}
+		case <-tick:
+			export()
+		}
+	}
+}
+
+func (s *OTelSink) export(batch []OTelRecord) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opt.timeout)
+	defer cancel()
+
+	if err := s.exp.Export(ctx, batch); err != nil {
+		s.err.Store(&err)
+	}
+}
+
+// WriteEntry queues e for export. Errors of the exporter are returned by Flush and Close.
+func (s *OTelSink) WriteEntry(e *Entry) error {
+	r := newOTelRecord(e, time.Now())
+	r.Resource = s.opt.resource
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return ErrClosed
+	}
+
+	item := otelItem{r: r, done: nil}
+
+	if s.opt.policy == Overflow_Drop {
+		select {
+		case s.queue <- item:
+		default:
+			s.dropped.Add(1)
+		}
+
+		return nil
+	}
+
+	s.queue <- item
+
+	return nil
+}
+
+// Dropped returns the number of records discarded by Overflow_Drop.
+func (s *OTelSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Flush waits until the records queued before the call are exported,
+// and returns the last error of the exporter.
+func (s *OTelSink) Flush() error {
+	s.mu.RLock()
+
+	if s.closed {
+		s.mu.RUnlock()
+		return s.lastError()
+	}
+
+	done := make(chan struct{})
+	s.queue <- otelItem{r: OTelRecord{}, done: done} //nolint:exhaustruct
+	s.mu.RUnlock()
+
+	<-done
+
+	return s.lastError()
+}
+
+// Close exports the queued records and stops the goroutine.
+func (s *OTelSink) Close() error {
+	s.mu.Lock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+
+	s.mu.Unlock()
+
+	<-s.done
+
+	return s.lastError()
+}
+
+func (s *OTelSink) lastError() error {
+	if p := s.err.Load(); p != nil {
+		return *p
+	}
+
+	return nil
+}
+
+func newOTelRecord(e *Entry, now time.Time) OTelRecord {
+	keys := make([]string, 0, len(e.Labels))
+	for k := range e.Labels {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	attrs := make([]Field, 0, len(keys)+len(e.Fields))
+	for _, k := range keys {
+		attrs = append(attrs, String(k, e.Labels[k]))
+	}
+
+	attrs = append(attrs, e.Fields...)
+	attrs = append(attrs, sourceFields(e.Source)...)
+
+	ts := e.Time
+	if ts.IsZero() {
+		ts = now
+	}
+
+	return OTelRecord{
+		Timestamp:         ts,
+		ObservedTimestamp: now,
+		SeverityNumber:    e.Severity.OTelSeverity(),
+		SeverityText:      e.Severity.String(),
+		Body:              e.Message,
+		Attributes:        attrs,
+		TraceID:           traceID(e.Trace),
+		SpanID:            e.SpanID,
+		Resource:          nil,
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/taomics/go-pkg/log/otel
+
+go 1.23.0
+
+require go.opentelemetry.io/otel/trace v1.38.0
+
+require go.opentelemetry.io/otel v1.38.0 // indirect
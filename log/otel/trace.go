@@ -0,0 +1,23 @@
+// Package otel correlates the entries of github.com/taomics/go-pkg/log with OpenTelemetry spans.
+// It is a separate module so that the log module does not depend on OpenTelemetry.
+//
+//	log.SetTraceExtractor(otel.TraceFromContext)
+//	log.SetTraceProject(projectID) // for Format_GoogleCloud
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceFromContext returns the trace and span IDs of the span in ctx, or empty strings
+// if ctx has no valid span. It is a log.TraceExtractor.
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+
+	return sc.TraceID().String(), sc.SpanID().String()
+}
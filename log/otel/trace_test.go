@@ -0,0 +1,40 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/taomics/go-pkg/log/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceFromContext(t *testing.T) {
+	t.Parallel()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+
+	//nolint:exhaustruct
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantTrace string
+		wantSpan  string
+	}{
+		{"span", trace.ContextWithSpanContext(context.Background(), sc), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+		{"no span", context.Background(), "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotTrace, gotSpan := otel.TraceFromContext(tt.ctx)
+			if gotTrace != tt.wantTrace || gotSpan != tt.wantSpan {
+				t.Errorf("want %q %q, got %q %q", tt.wantTrace, tt.wantSpan, gotTrace, gotSpan)
+			}
+		})
+	}
+}
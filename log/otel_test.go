@@ -0,0 +1,198 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+	"github.com/taomics/go-pkg/log/logtest"
+)
+
+func TestOTelSink(t *testing.T) {
+	t.Parallel()
+
+	exp := new(logtest.InMemoryExporter)
+	sink := log.NewOTelSink(exp,
+		log.WithOTelBatchSize(2),
+		log.WithOTelFlushInterval(0),
+		log.WithOTelResource(log.String("service.name", "api")),
+	)
+	l := log.New(log.WithOnlySinks(sink))
+
+	ctx := log.ContextWithTrace(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+
+	l.LogContext(ctx, &log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_WARNING,
+		Message:  "slow",
+		Labels:   map[string]string{"grpc_method": "/test.Service/Get"},
+		Fields:   []log.Field{log.Int("attempt", 2)},
+	})
+
+	if n := len(exp.Records()); n != 0 {
+		t.Fatalf("want batched, got %d records", n)
+	}
+
+	l.Log(&log.Entry{ //nolint:exhaustruct
+		Severity: log.Severity_CRITICAL,
+		Message:  "down",
+		Trace:    "projects/p/traces/0af7651916cd43dd8448eb211c80319c",
+	})
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	records := exp.Records()
+	if len(records) != 2 {
+		t.Fatalf("want 2 records, got %d", len(records))
+	}
+
+	r := records[0]
+	if r.SeverityNumber != log.OTelSeverity_WARN || r.SeverityText != "WARNING" || r.Body != "slow" {
+		t.Errorf("want WARN slow, got %d %s %s", r.SeverityNumber, r.SeverityText, r.Body)
+	}
+
+	if r.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || r.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("trace from context: got %s %s", r.TraceID, r.SpanID)
+	}
+
+	if len(r.Attributes) != 2 || r.Attributes[0].Key != "grpc_method" || r.Attributes[1].Key != "attempt" {
+		t.Errorf("attributes: got %v", r.Attributes)
+	}
+
+	if len(r.Resource) != 1 || r.Resource[0].Value != "api" {
+		t.Errorf("resource: got %v", r.Resource)
+	}
+
+	if r := records[1]; r.SeverityNumber != log.OTelSeverity_ERROR2 || r.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("want ERROR2 with trace, got %d %s", r.SeverityNumber, r.TraceID)
+	}
+}
+
+type blockingExporter struct {
+	started chan struct{}
+	release chan struct{}
+	n       atomic.Int64
+}
+
+func (e *blockingExporter) Export(_ context.Context, records []log.OTelRecord) error {
+	select {
+	case e.started <- struct{}{}:
+	default:
+	}
+
+	<-e.release
+	e.n.Add(int64(len(records)))
+
+	return nil
+}
+
+func TestOTelSink_slowExporter(t *testing.T) {
+	t.Parallel()
+
+	exp := &blockingExporter{started: make(chan struct{}, 1), release: make(chan struct{}), n: atomic.Int64{}}
+	sink := log.NewOTelSink(exp, log.WithOTelBatchSize(1), log.WithOTelQueueSize(4))
+	l := log.New(log.WithOnlySinks(sink))
+
+	l.Println("first")
+	<-exp.started
+
+	written := make(chan struct{})
+
+	go func() {
+		defer close(written)
+
+		for range 9 {
+			l.Println("hello")
+		}
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging blocked on the exporter")
+	}
+
+	// One record is being exported, 4 are queued and the rest are dropped.
+	if got := sink.Dropped(); got != 5 {
+		t.Errorf("want 5 dropped, got %d", got)
+	}
+
+	close(exp.release)
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := exp.n.Load(); got != 5 {
+		t.Errorf("want 5 exported, got %d", got)
+	}
+
+	if err := sink.WriteEntry(&log.Entry{Message: "late"}); !errors.Is(err, log.ErrClosed) { //nolint:exhaustruct
+		t.Errorf("want ErrClosed, got %v", err)
+	}
+}
+
+func TestOTelSink_flushInterval(t *testing.T) {
+	t.Parallel()
+
+	exp := new(logtest.InMemoryExporter)
+	sink := log.NewOTelSink(exp, log.WithOTelFlushInterval(10*time.Millisecond))
+	defer sink.Close()
+
+	l := log.New(log.WithOnlySinks(sink))
+	l.Println("hello")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(exp.Records()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch not exported")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLogger_LogContext_extractor(t *testing.T) {
+	t.Parallel()
+
+	l, rec := logtest.NewLogger(log.WithTraceExtractor(func(context.Context) (string, string) {
+		return "trace", "span"
+	}))
+
+	l.LogContext(context.Background(), &log.Entry{Message: "hello"}) //nolint:exhaustruct
+
+	if e := rec.Entries()[0]; e.Trace != "trace" || e.SpanID != "span" {
+		t.Errorf("want trace span, got %q %q", e.Trace, e.SpanID)
+	}
+}
+
+func TestLogger_LogContext_traceProject(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	l := log.New(
+		log.WithOutput(&buf),
+		log.WithStructuredLogging(true),
+		log.WithTraceProject("my-project"),
+		log.WithTraceExtractor(func(context.Context) (string, string) {
+			return "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+		}),
+	)
+
+	l.LogContext(context.Background(), &log.Entry{Message: "from context"})     //nolint:exhaustruct
+	l.Log(&log.Entry{Message: "qualified", Trace: "projects/other/traces/abc"}) //nolint:exhaustruct
+
+	want := `{"severity":"INFO","message":"from context",` +
+		`"logging.googleapis.com/trace":"projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736",` +
+		`"logging.googleapis.com/spanId":"00f067aa0ba902b7"}` + "\n" +
+		`{"severity":"INFO","message":"qualified","logging.googleapis.com/trace":"projects/other/traces/abc"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
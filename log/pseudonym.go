@@ -0,0 +1,168 @@
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+var ErrInvalidPseudonymKey = errors.New("invalid pseudonym key")
+
+const (
+	minPseudonymSecretLen = 16
+	pseudonymHashLen      = 8 // bytes of HMAC-SHA256 kept in a token
+
+	PseudonymKind_Email     = "email"
+	PseudonymKind_UserID    = "user"
+	PseudonymKind_IPAddress = "ip"
+)
+
+var rePseudonymKeyID = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// PseudonymKey is a secret for Pseudonymizer. ID is embedded in tokens to tell which key made them.
+type PseudonymKey struct {
+	ID     string
+	Secret []byte
+}
+
+// Pseudonymizer produces stable and non-reversible tokens of identifiers with a keyed HMAC,
+// so that one user's activity can be followed across log lines without logging the identifier.
+// A token looks like "email_k2_9f86d081884c7d65".
+type Pseudonymizer struct {
+	keys []PseudonymKey // keys[0] is the current key
+}
+
+// NewPseudonymizer returns a Pseudonymizer making tokens with current.
+// previous keys are kept for Tokens and Match while logs made with them are still searched.
+func NewPseudonymizer(current PseudonymKey, previous ...PseudonymKey) (*Pseudonymizer, error) {
+	keys := append([]PseudonymKey{current}, previous...)
+
+	for _, k := range keys {
+		if !rePseudonymKeyID.MatchString(k.ID) {
+			return nil, fmt.Errorf("%w: id should be alphanumeric: %q", ErrInvalidPseudonymKey, k.ID)
+		}
+
+		if len(k.Secret) < minPseudonymSecretLen {
+			return nil, fmt.Errorf("%w: secret of %s should be at least %d bytes", ErrInvalidPseudonymKey, k.ID, minPseudonymSecretLen)
+		}
+	}
+
+	return &Pseudonymizer{keys: keys}, nil
+}
+
+// Pseudonymize returns the token of value of the kind with the current key.
+// The same kind and value always give the same token while the key is unchanged.
+func (p *Pseudonymizer) Pseudonymize(kind, value string) string {
+	return token(p.keys[0], kind, value)
+}
+
+// Tokens returns the tokens of value with all keys, the current key first.
+// It is useful for searching logs written before a key rotation.
+func (p *Pseudonymizer) Tokens(kind, value string) []string {
+	tokens := make([]string, len(p.keys))
+	for i, k := range p.keys {
+		tokens[i] = token(k, kind, value)
+	}
+
+	return tokens
+}
+
+// Match reports whether tok is the token of value made by any of the keys.
+func (p *Pseudonymizer) Match(tok, kind, value string) bool {
+	for _, t := range p.Tokens(kind, value) {
+		if hmac.Equal([]byte(t), []byte(tok)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Email returns the token of an email address. Case and surrounding spaces are ignored.
+func (p *Pseudonymizer) Email(email string) string {
+	return p.Pseudonymize(PseudonymKind_Email, strings.ToLower(strings.TrimSpace(email)))
+}
+
+func (p *Pseudonymizer) UserID(id string) string {
+	return p.Pseudonymize(PseudonymKind_UserID, id)
+}
+
+// IPAddress returns the token of an IP address. Equivalent notations give the same token.
+func (p *Pseudonymizer) IPAddress(ipStr string) string {
+	if ip := net.ParseIP(ipStr); ip != nil {
+		ipStr = ip.String()
+	}
+
+	return p.Pseudonymize(PseudonymKind_IPAddress, ipStr)
+}
+
+// EmailRule is a RedactRule replacing email addresses with tokens instead of MaskEmail.
+func (p *Pseudonymizer) EmailRule() RedactRule {
+	return func(s string) string {
+		return reEmail.ReplaceAllStringFunc(s, p.Email)
+	}
+}
+
+// IPAddressRule is a RedactRule replacing IP addresses with tokens instead of MaskIPAddress.
+func (p *Pseudonymizer) IPAddressRule() RedactRule {
+	f := func(s string) string {
+		if net.ParseIP(s) == nil {
+			return s
+		}
+
+		return p.IPAddress(s)
+	}
+
+	return func(s string) string {
+		s = reIPv4.ReplaceAllStringFunc(s, f)
+		return reIPv6.ReplaceAllStringFunc(s, f)
+	}
+}
+
+func token(k PseudonymKey, kind, value string) string {
+	mac := hmac.New(sha256.New, k.Secret)
+	mac.Write([]byte(kind + "\x00" + value))
+
+	return kind + "_" + k.ID + "_" + hex.EncodeToString(mac.Sum(nil)[:pseudonymHashLen])
+}
+
+var defaultPseudonymizer atomic.Pointer[Pseudonymizer]
+
+// SetPseudonymizer sets the Pseudonymizer used by PseudonymizeEmail, PseudonymizeUserID
+// and PseudonymizeIPAddress. nil makes them fall back to the masks.
+func SetPseudonymizer(p *Pseudonymizer) {
+	defaultPseudonymizer.Store(p)
+}
+
+// PseudonymizeEmail returns the token of email, or MaskEmail(email) if no Pseudonymizer is set.
+func PseudonymizeEmail(email string) string {
+	if p := defaultPseudonymizer.Load(); p != nil {
+		return p.Email(email)
+	}
+
+	return MaskEmail(email)
+}
+
+// PseudonymizeUserID returns the token of id, or "" if no Pseudonymizer is set.
+func PseudonymizeUserID(id string) string {
+	if p := defaultPseudonymizer.Load(); p != nil {
+		return p.UserID(id)
+	}
+
+	return ""
+}
+
+// PseudonymizeIPAddress returns the token of ipStr, or MaskIPAddress(ipStr) if no Pseudonymizer is set.
+func PseudonymizeIPAddress(ipStr string) string {
+	if p := defaultPseudonymizer.Load(); p != nil {
+		return p.IPAddress(ipStr)
+	}
+
+	return MaskIPAddress(ipStr)
+}
@@ -0,0 +1,76 @@
+package log_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	t.Parallel()
+
+	k1 := log.PseudonymKey{ID: "k1", Secret: []byte("0123456789abcdef")}
+	k2 := log.PseudonymKey{ID: "k2", Secret: []byte("fedcba9876543210")}
+
+	p1, err := log.NewPseudonymizer(k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := p1.Email("Taomics@example.com ")
+	if b := p1.Email("taomics@example.com"); a != b {
+		t.Errorf("tokens should be stable: %s != %s", a, b)
+	}
+
+	if !regexp.MustCompile(`^email_k1_[0-9a-f]{16}$`).MatchString(a) {
+		t.Errorf("unexpected token format: %s", a)
+	}
+
+	if b := p1.Email("taomics2@example.com"); a == b {
+		t.Errorf("tokens of different emails should differ: %s", a)
+	}
+
+	if b := p1.UserID("taomics@example.com"); a[len("email_k1_"):] == b[len("user_k1_"):] {
+		t.Errorf("tokens of different kinds should differ: %s, %s", a, b)
+	}
+
+	if p1.IPAddress("2001:db8::1") != p1.IPAddress("2001:0db8:0:0:0:0:0:1") {
+		t.Errorf("equivalent IP addresses should have the same token")
+	}
+
+	// rotate k1 to k2
+	p2, err := log.NewPseudonymizer(k2, k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := p2.Email("taomics@example.com"); c == a {
+		t.Errorf("the current key should be used: %s", c)
+	}
+
+	if !p2.Match(a, log.PseudonymKind_Email, "taomics@example.com") {
+		t.Errorf("the token of the previous key should match")
+	}
+
+	if _, err := log.NewPseudonymizer(log.PseudonymKey{ID: "k3", Secret: []byte("short")}); !errors.Is(err, log.ErrInvalidPseudonymKey) {
+		t.Errorf("want ErrInvalidPseudonymKey, got %v", err)
+	}
+}
+
+func TestPseudonymizer_rule(t *testing.T) {
+	t.Parallel()
+
+	p, err := log.NewPseudonymizer(log.PseudonymKey{ID: "k1", Secret: []byte("0123456789abcdef")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := log.NewRedactor(p.EmailRule(), p.IPAddressRule(), log.RedactEmail)
+
+	want := "login " + p.Email("taomics@example.com") + " from " + p.IPAddress("10.1.2.3")
+	if got := r.Redact("login taomics@example.com from 10.1.2.3"); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,198 @@
+package log
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// RedactRule rewrites sensitive parts of s. Any func(string) string can be used as a custom rule.
+type RedactRule func(s string) string
+
+// RedactPattern returns a rule replacing every match of re with replacement.
+// replacement may refer to submatches like regexp.Regexp.ReplaceAllString.
+func RedactPattern(re *regexp.Regexp, replacement string) RedactRule {
+	return func(s string) string {
+		return re.ReplaceAllString(s, replacement)
+	}
+}
+
+var (
+	reBearer     = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	reJWT        = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	reEmail      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	reCreditCard = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	reIPv4       = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	reIPv6       = regexp.MustCompile(`[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}`)
+	rePhone      = regexp.MustCompile(`(?:\+\d{1,3}[ -]?\d{1,4}[ -]?\d{1,4}[ -]?\d{3,4}|\b0\d{1,4}-\d{1,4}-\d{3,4}|\b0[5789]0\d{8})\b`)
+)
+
+var (
+	// RedactBearerToken replaces the credentials of "Bearer xxx" such as Authorization header values.
+	RedactBearerToken RedactRule = func(s string) string {
+		return reBearer.ReplaceAllStringFunc(s, func(m string) string {
+			return m[:len("bearer")] + " " + redacted
+		})
+	}
+
+	// RedactJWT replaces JSON Web Tokens.
+	RedactJWT = RedactPattern(reJWT, redacted)
+
+	// RedactEmail masks email addresses with MaskEmail.
+	RedactEmail RedactRule = func(s string) string {
+		return reEmail.ReplaceAllStringFunc(s, MaskEmail)
+	}
+
+	// RedactCreditCard replaces 13 to 19 digit numbers which pass the Luhn check.
+	RedactCreditCard RedactRule = func(s string) string {
+		return reCreditCard.ReplaceAllStringFunc(s, func(m string) string {
+			if !luhn(m) {
+				return m
+			}
+
+			return redacted
+		})
+	}
+
+	// RedactIPAddress masks IPv4 and IPv6 addresses with MaskIPAddress.
+	RedactIPAddress RedactRule = func(s string) string {
+		s = reIPv4.ReplaceAllStringFunc(s, maskIPCandidate)
+		return reIPv6.ReplaceAllStringFunc(s, maskIPCandidate)
+	}
+
+	// RedactPhoneNumber replaces international (+81 90-1234-5678) and Japanese domestic
+	// (03-1234-5678, 09012345678) phone numbers.
+	RedactPhoneNumber = RedactPattern(rePhone, redacted)
+)
+
+// DefaultRedactRules returns the built-in rules in the order they should be applied.
+func DefaultRedactRules() []RedactRule {
+	return []RedactRule{
+		RedactBearerToken,
+		RedactJWT,
+		RedactEmail,
+		RedactCreditCard,
+		RedactIPAddress,
+		RedactPhoneNumber,
+	}
+}
+
+// Redactor applies rules to the message, the label values, and the string values of the fields
+// including those nested in Object and in slices of strings, fields and any. Other values, such as
+// structs and maps passed with Any, and keys are not redacted; mask them before logging,
+// e.g. with MaskStruct.
+type Redactor struct {
+	rules []RedactRule
+}
+
+func NewRedactor(rules ...RedactRule) *Redactor {
+	return &Redactor{rules: rules}
+}
+
+func (r *Redactor) Redact(s string) string {
+	for _, rule := range r.rules {
+		s = rule(s)
+	}
+
+	return s
+}
+
+// redactEntry returns a copy of e with redacted message, labels and string fields.
+func (r *Redactor) redactEntry(e *Entry) *Entry {
+	ne := *e
+	ne.Message = r.Redact(e.Message)
+
+	if len(e.Labels) > 0 {
+		ne.Labels = make(map[string]string, len(e.Labels))
+		for k, v := range e.Labels {
+			ne.Labels[k] = r.Redact(v)
+		}
+	}
+
+	if len(e.Fields) > 0 {
+		ne.Fields = r.redactFields(e.Fields)
+	}
+
+	return &ne
+}
+
+// redactFields redacts string values of fields including nested objects.
+func (r *Redactor) redactFields(fields []Field) []Field {
+	rfs := make([]Field, len(fields))
+
+	for i, f := range fields {
+		f.Value = r.redactValue(f.Value)
+		rfs[i] = f
+	}
+
+	return rfs
+}
+
+// redactValue redacts strings, objects and slices of them such as error_chain of ReportError.
+func (r *Redactor) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.Redact(v)
+	case Fields:
+		return Fields(r.redactFields(v))
+	case []Field:
+		return r.redactFields(v)
+	case []Fields:
+		rv := make([]Fields, len(v))
+		for i, fs := range v {
+			rv[i] = r.redactFields(fs)
+		}
+
+		return rv
+	case []string:
+		rv := make([]string, len(v))
+		for i, s := range v {
+			rv[i] = r.Redact(s)
+		}
+
+		return rv
+	case []any:
+		rv := make([]any, len(v))
+		for i, e := range v {
+			rv[i] = r.redactValue(e)
+		}
+
+		return rv
+	default:
+		return v
+	}
+}
+
+func maskIPCandidate(s string) string {
+	if net.ParseIP(s) == nil {
+		return s
+	}
+
+	return MaskIPAddress(s)
+}
+
+//nolint:mnd
+func luhn(s string) bool {
+	s = strings.NewReplacer(" ", "", "-", "").Replace(s)
+
+	sum := 0
+	double := false
+
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
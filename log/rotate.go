@@ -0,0 +1,499 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	backupTimeFormat = "20060102T150405.000"
+
+	// rotateRetryInterval is how long Write waits before retrying a failed rotation.
+	rotateRetryInterval = 10 * time.Second
+)
+
+type rotateOption struct {
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	reopen     bool
+	now        func() time.Time
+}
+
+type RotateOption func(*rotateOption)
+
+// WithMaxSize rotates the file before it exceeds n bytes. The default is 100 MiB; 0 disables it.
+func WithMaxSize(n int64) RotateOption {
+	return func(o *rotateOption) {
+		o.maxSize = n
+	}
+}
+
+// WithRotateInterval rotates the file every d, aligned to UTC, e.g. 24*time.Hour at midnight.
+func WithRotateInterval(d time.Duration) RotateOption {
+	return func(o *rotateOption) {
+		o.interval = d
+	}
+}
+
+// WithMaxBackups keeps at most n rotated files. 0 keeps all.
+func WithMaxBackups(n int) RotateOption {
+	return func(o *rotateOption) {
+		o.maxBackups = n
+	}
+}
+
+// WithMaxAge removes rotated files older than d. 0 keeps all.
+func WithMaxAge(d time.Duration) RotateOption {
+	return func(o *rotateOption) {
+		o.maxAge = d
+	}
+}
+
+// WithCompression gzips rotated files.
+func WithCompression(enabled bool) RotateOption {
+	return func(o *rotateOption) {
+		o.compress = enabled
+	}
+}
+
+// WithReopenOnSIGHUP reopens the file on SIGHUP, e.g. after logrotate moved it.
+func WithReopenOnSIGHUP() RotateOption {
+	return func(o *rotateOption) {
+		o.reopen = true
+	}
+}
+
+func withRotateClock(now func() time.Time) RotateOption {
+	return func(o *rotateOption) {
+		o.now = now
+	}
+}
+
+// RotatingFile is an io.Writer appending to a file and rotating it by size and time.
+// Rotated files are renamed to <name>-<UTC time><ext>, or <name>-<UTC time>-<n><ext> if the name
+// is taken, gzipped if enabled, and removed by retention in the background.
+// If a rotation fails, Write keeps appending to the current file and retries later. Use it as an output or with NewWriterSink:
+//
+//	f, err := log.OpenRotatingFile("/var/log/app/app.log", log.WithMaxBackups(7), log.WithCompression(true))
+//	l := log.New(log.WithOnlySinks(log.NewWriterSink(f, log.Format_GoogleCloud, log.Severity_INFO)))
+type RotatingFile struct {
+	path string
+	opt  rotateOption
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	rotateAt time.Time
+	retryAt  time.Time // after a failed rotation
+	closed   bool
+
+	millMu  sync.Mutex // serializes compression and removal
+	millWG  sync.WaitGroup
+	signals chan os.Signal
+}
+
+// OpenRotatingFile opens or creates the file at path for appending, creating its directory.
+func OpenRotatingFile(path string, opts ...RotateOption) (*RotatingFile, error) {
+	opt := rotateOption{
+		maxSize:    100 << 20, //nolint:mnd
+		interval:   0,
+		maxBackups: 0,
+		maxAge:     0,
+		compress:   false,
+		reopen:     false,
+		now:        time.Now,
+	}
+
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	//nolint:exhaustruct
+	rf := &RotatingFile{
+		path: path,
+		opt:  opt,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:mnd
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	if opt.reopen {
+		rf.signals = make(chan os.Signal, 1)
+		signal.Notify(rf.signals, syscall.SIGHUP)
+
+		go rf.handleSignals()
+	}
+
+	return rf, nil
+}
+
+// Write appends p to the file, rotating it first if needed. p is never split across files.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return 0, ErrClosed
+	}
+
+	if rf.shouldRotate(len(p)) && !rf.opt.now().Before(rf.retryAt) {
+		if err := rf.rotate(); err != nil {
+			rf.retryAt = rf.opt.now().Add(rotateRetryInterval)
+			log.Printf("go-pkg/log: rotate %s: %v", rf.path, err)
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+
+	return n, err //nolint:wrapcheck
+}
+
+// Rotate rotates the file now.
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return ErrClosed
+	}
+
+	return rf.rotate()
+}
+
+// Reopen reopens the file at the path, which may have been moved by another tool.
+// If the file cannot be opened, the current one stays in use.
+func (rf *RotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return ErrClosed
+	}
+
+	old := rf.file
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	return nil
+}
+
+// Flush commits the file to stable storage.
+func (rf *RotatingFile) Flush() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return nil
+	}
+
+	return rf.file.Sync() //nolint:wrapcheck
+}
+
+// Close closes the file and waits for the background compression and removal.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+
+	if rf.closed {
+		rf.mu.Unlock()
+		return nil
+	}
+
+	rf.closed = true
+
+	if rf.signals != nil {
+		signal.Stop(rf.signals)
+		close(rf.signals)
+	}
+
+	err := rf.file.Close()
+
+	rf.mu.Unlock()
+	rf.millWG.Wait()
+
+	return err //nolint:wrapcheck
+}
+
+func (rf *RotatingFile) handleSignals() {
+	for range rf.signals {
+		if err := rf.Reopen(); err != nil && !errors.Is(err, ErrClosed) {
+			log.Printf("go-pkg/log: reopen %s: %v", rf.path, err)
+		}
+	}
+}
+
+// open opens the file at the path. rf.mu must be held.
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644) //nolint:mnd
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+
+	rf.file = f
+	rf.size = fi.Size()
+
+	if rf.opt.interval > 0 {
+		rf.rotateAt = rf.opt.now().UTC().Truncate(rf.opt.interval).Add(rf.opt.interval)
+	}
+
+	return nil
+}
+
+func (rf *RotatingFile) shouldRotate(n int) bool {
+	if rf.opt.maxSize > 0 && rf.size > 0 && rf.size+int64(n) > rf.opt.maxSize {
+		return true
+	}
+
+	return rf.opt.interval > 0 && !rf.opt.now().Before(rf.rotateAt)
+}
+
+// rotate renames the file to a backup and opens a new one. rf.mu must be held.
+// The current file is renamed while open and closed only after the new one is opened,
+// so that Write can continue with it if the rotation fails.
+func (rf *RotatingFile) rotate() error {
+	backup, err := rf.backupName(rf.opt.now())
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(rf.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("rename log file: %w", err)
+	}
+
+	old := rf.file
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	if err := old.Close(); err != nil {
+		log.Printf("go-pkg/log: close %s: %v", backup, err)
+	}
+
+	rf.retryAt = time.Time{}
+	rf.millWG.Add(1)
+
+	go func() {
+		defer rf.millWG.Done()
+
+		rf.mill()
+	}()
+
+	return nil
+}
+
+// backupName returns a name for a backup rotated at t which is not used by any file, including
+// compressed ones, so that rotations in the same millisecond never overwrite each other.
+func (rf *RotatingFile) backupName(t time.Time) (string, error) {
+	dir, name := filepath.Split(rf.path)
+	ext := filepath.Ext(name)
+	base := filepath.Join(dir, strings.TrimSuffix(name, ext)+"-"+t.UTC().Format(backupTimeFormat))
+
+	for n := 0; ; n++ {
+		p := base + ext
+		if n > 0 {
+			p = base + "-" + strconv.Itoa(n) + ext
+		}
+
+		free, err := isFree(p)
+		if err != nil {
+			return "", err
+		}
+
+		if free {
+			return p, nil
+		}
+	}
+}
+
+// isFree reports whether neither path nor its compressed form exists.
+func isFree(path string) (bool, error) {
+	for _, p := range []string{path, path + ".gz"} {
+		_, err := os.Lstat(p)
+
+		switch {
+		case err == nil:
+			return false, nil
+		case !errors.Is(err, os.ErrNotExist):
+			return false, fmt.Errorf("stat backup: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+// mill removes the backups out of retention and compresses the others.
+// It works on all backups, so it does not matter which rotation it runs for.
+func (rf *RotatingFile) mill() {
+	rf.millMu.Lock()
+	defer rf.millMu.Unlock()
+
+	if !rf.opt.compress && rf.opt.maxBackups <= 0 && rf.opt.maxAge <= 0 {
+		return
+	}
+
+	backups, err := rf.backups()
+	if err != nil {
+		log.Printf("go-pkg/log: list backups of %s: %v", rf.path, err)
+		return
+	}
+
+	cutoff := rf.opt.now().Add(-rf.opt.maxAge)
+
+	for i, b := range backups {
+		if (rf.opt.maxBackups > 0 && i >= rf.opt.maxBackups) || (rf.opt.maxAge > 0 && b.t.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil {
+				log.Printf("go-pkg/log: remove %s: %v", b.path, err)
+			}
+
+			continue
+		}
+
+		if rf.opt.compress && !strings.HasSuffix(b.path, ".gz") {
+			if err := compressFile(b.path); err != nil {
+				log.Printf("go-pkg/log: compress %s: %v", b.path, err)
+			}
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	t    time.Time
+	n    int // the suffix of the backups rotated in the same millisecond
+}
+
+// backups returns the rotated files, newest first.
+func (rf *RotatingFile) backups() ([]backupFile, error) {
+	dir, name := filepath.Split(rf.path)
+	ext := filepath.Ext(name)
+	prefix := strings.TrimSuffix(name, ext) + "-"
+
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	var backups []backupFile
+
+	for _, e := range entries {
+		s, ok := strings.CutPrefix(e.Name(), prefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+
+		s = strings.TrimSuffix(s, ".gz")
+
+		s, ok = strings.CutSuffix(s, ext)
+		if !ok {
+			continue
+		}
+
+		n := 0
+
+		if len(s) > len(backupTimeFormat) {
+			if n, err = strconv.Atoi(strings.TrimPrefix(s[len(backupTimeFormat):], "-")); err != nil || n <= 0 {
+				continue
+			}
+
+			s = s[:len(backupTimeFormat)]
+		}
+
+		t, err := time.Parse(backupTimeFormat, s)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backupFile{filepath.Join(dir, e.Name()), t, n})
+	}
+
+	slices.SortFunc(backups, func(a, b backupFile) int {
+		if c := b.t.Compare(a.t); c != 0 {
+			return c
+		}
+
+		return b.n - a.n
+	})
+
+	return backups, nil
+}
+
+// compressFile gzips path to path.gz and removes path.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644) //nolint:mnd
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+
+	if _, err = io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err //nolint:wrapcheck
+	}
+
+	if err = zw.Close(); err != nil {
+		dst.Close()
+		return err //nolint:wrapcheck
+	}
+
+	if err = dst.Close(); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if err = os.Rename(tmp, path+".gz"); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return os.Remove(path) //nolint:wrapcheck
+}
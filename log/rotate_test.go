@@ -0,0 +1,307 @@
+package log_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+func TestRotatingFile_size(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	f, err := log.OpenRotatingFile(filepath.Join(dir, "app.log"),
+		log.WithMaxSize(10),
+		log.WithMaxBackups(2),
+		log.WithCompression(true),
+		log.WithRotateClock(clock.now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		clock.advance(time.Second)
+
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := dirNames(t, dir)
+	want := []string{"app-20240501T000003.000.log.gz", "app-20240501T000004.000.log.gz", "app.log"}
+
+	if !slices.Equal(names, want) {
+		t.Fatalf("want %v, got %v", want, names)
+	}
+
+	if got := readGzip(t, filepath.Join(dir, names[1])); got != "line 3\n" {
+		t.Errorf("backup: want %q, got %q", "line 3\n", got)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(dir, "app.log")); string(got) != "line 4\n" {
+		t.Errorf("current: want %q, got %q", "line 4\n", got)
+	}
+}
+
+func TestRotatingFile_burst(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	f, err := log.OpenRotatingFile(filepath.Join(dir, "app.log"),
+		log.WithMaxSize(100),
+		log.WithRotateClock(clock.now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := []byte(strings.Repeat("x", 99) + "\n")
+
+	for range 50 {
+		if _, err := f.Write(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := dirNames(t, dir)
+	if len(names) != 50 {
+		t.Fatalf("want 50 files, got %d: %v", len(names), names)
+	}
+
+	var total int64
+
+	for _, name := range names {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		total += fi.Size()
+	}
+
+	if want := int64(50 * len(line)); total != want {
+		t.Errorf("want %d bytes kept, got %d", want, total)
+	}
+}
+
+func TestRotatingFile_burstRetention(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	f, err := log.OpenRotatingFile(filepath.Join(dir, "app.log"),
+		log.WithMaxSize(10),
+		log.WithMaxBackups(2),
+		log.WithRotateClock(clock.now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := dirNames(t, dir)
+	want := []string{"app-20240501T000000.000-1.log", "app-20240501T000000.000-2.log", "app.log"}
+
+	if !slices.Equal(names, want) {
+		t.Fatalf("want %v, got %v", want, names)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(dir, names[1])); string(got) != "line 3\n" {
+		t.Errorf("newest backup: want %q, got %q", "line 3\n", got)
+	}
+}
+
+func TestRotatingFile_rotateError(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	// The backup name is longer than the file name limit, so renaming fails.
+	path := filepath.Join(dir, strings.Repeat("a", 240)+".log")
+
+	f, err := log.OpenRotatingFile(path,
+		log.WithMaxSize(10),
+		log.WithRotateClock(clock.now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := f.Rotate(); err == nil {
+		t.Error("want rotate error")
+	}
+
+	for _, s := range []string{"line 1\n", "line 2\n"} {
+		if _, err := f.Write([]byte(s)); err != nil {
+			t.Fatalf("write after failed rotation: %v", err)
+		}
+	}
+
+	if got, _ := os.ReadFile(path); string(got) != "line 1\nline 2\n" {
+		t.Errorf("want %q, got %q", "line 1\nline 2\n", got)
+	}
+
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Write([]byte("line 3\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := os.ReadFile(path); string(got) != "line 1\nline 2\nline 3\n" {
+		t.Errorf("want %q, got %q", "line 1\nline 2\nline 3\n", got)
+	}
+}
+
+func TestRotatingFile_interval(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 23, 59, 59, 0, time.UTC)}
+
+	f, err := log.OpenRotatingFile(filepath.Join(dir, "app.log"),
+		log.WithRotateInterval(24*time.Hour),
+		log.WithMaxAge(time.Hour),
+		log.WithRotateClock(clock.now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	f.Write([]byte("day 1\n"))
+
+	clock.advance(time.Second)
+	f.Write([]byte("day 2\n"))
+
+	clock.advance(24 * time.Hour)
+	f.Write([]byte("day 3\n"))
+	f.Close()
+
+	names := dirNames(t, dir)
+	want := []string{"app-20240503T000000.000.log", "app.log"}
+
+	if !slices.Equal(names, want) {
+		t.Fatalf("want %v, got %v", want, names)
+	}
+}
+
+func TestRotatingFile_Reopen(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := log.OpenRotatingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	f.Write([]byte("before\n"))
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Write([]byte("after\n"))
+
+	if got, _ := os.ReadFile(path); string(got) != "after\n" {
+		t.Errorf("want %q, got %q", "after\n", got)
+	}
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+
+	return names
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if _, err := io.Copy(&sb, zr); err != nil {
+		t.Fatal(err)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const maxSampleKeys = 10000
+
+type samplerOption struct {
+	key func(*Entry) string
+	now func() time.Time
+}
+
+type SamplerOption func(*samplerOption)
+
+// WithSampleKey sets the function grouping entries to be sampled together.
+// The default key is the severity and the message.
+func WithSampleKey(f func(*Entry) string) SamplerOption {
+	return func(o *samplerOption) {
+		o.key = f
+	}
+}
+
+// SampleByLabel groups entries by the message and the value of the label,
+// e.g. SampleByLabel("grpc_method") for the entries of grpcutil.LogUnaryInterceptors.
+func SampleByLabel(label string) func(*Entry) string {
+	return func(e *Entry) string {
+		return e.Severity.String() + "\x00" + e.Message + "\x00" + e.Labels[label]
+	}
+}
+
+func withSamplerClock(now func() time.Time) SamplerOption {
+	return func(o *samplerOption) {
+		o.now = now
+	}
+}
+
+// Sampler writes the first entries of each key in an interval and then one in thereafter.
+// Entries of ERROR and above are never sampled out.
+// At most 10000 keys are counted at a time. While all of them are in their interval,
+// entries of other keys are written without being counted.
+type Sampler struct {
+	interval   time.Duration
+	first      uint64
+	thereafter uint64
+	key        func(*Entry) string
+	now        func() time.Time
+
+	mu         sync.Mutex
+	counters   map[string]*sampleCounter
+	purgeAt    time.Time // when the oldest counter expires, if counters is full
+	suppressed atomic.Uint64
+}
+
+type sampleCounter struct {
+	start time.Time
+	n     uint64
+}
+
+// NewSampler returns a Sampler writing the first entries per key in every interval,
+// and then every thereafter-th entry. thereafter 0 suppresses all entries after the first.
+func NewSampler(interval time.Duration, first, thereafter uint64, opts ...SamplerOption) *Sampler {
+	opt := samplerOption{
+		key: func(e *Entry) string { return e.Severity.String() + "\x00" + e.Message },
+		now: time.Now,
+	}
+
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	//nolint:exhaustruct
+	return &Sampler{
+		interval:   interval,
+		first:      first,
+		thereafter: thereafter,
+		key:        opt.key,
+		now:        opt.now,
+		counters:   make(map[string]*sampleCounter),
+	}
+}
+
+// Sample reports whether e should be written, and counts e as suppressed if not.
+func (s *Sampler) Sample(e *Entry) bool {
+	if e.Severity >= Severity_ERROR {
+		return true
+	}
+
+	key := s.key(e)
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok || now.Sub(c.start) >= s.interval {
+		if !ok && len(s.counters) >= maxSampleKeys {
+			if !now.Before(s.purgeAt) {
+				s.purge(now)
+			}
+
+			if len(s.counters) >= maxSampleKeys {
+				return true
+			}
+		}
+
+		c = &sampleCounter{start: now, n: 0}
+		s.counters[key] = c
+	}
+
+	c.n++
+
+	if c.n <= s.first || (s.thereafter > 0 && (c.n-s.first)%s.thereafter == 0) {
+		return true
+	}
+
+	s.suppressed.Add(1)
+
+	return false
+}
+
+// Suppressed returns the number of entries sampled out so far.
+func (s *Sampler) Suppressed() uint64 {
+	return s.suppressed.Load()
+}
+
+// purge removes the counters whose interval has passed, and sets purgeAt to when the oldest
+// of the others expires, so that a full map is not scanned for every new key. s.mu must be held.
+func (s *Sampler) purge(now time.Time) {
+	var oldest time.Time
+
+	for k, c := range s.counters {
+		switch {
+		case now.Sub(c.start) >= s.interval:
+			delete(s.counters, k)
+		case oldest.IsZero() || c.start.Before(oldest):
+			oldest = c.start
+		}
+	}
+
+	s.purgeAt = oldest.Add(s.interval)
+}
@@ -0,0 +1,95 @@
+package log_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	s := log.NewSampler(time.Second, 2, 3,
+		log.WithSampleKey(log.SampleByLabel("grpc_method")),
+		log.WithSamplerClock(func() time.Time { return now }),
+	)
+
+	var buf bytes.Buffer
+
+	l := log.New(log.WithOutput(&buf), log.WithErrorOutput(&buf), log.WithStructuredLogging(true), log.WithSampler(s))
+
+	logRPC := func(method string, sev log.Severity) {
+		l.Log(&log.Entry{Severity: sev, Labels: map[string]string{"grpc_method": method}}) //nolint:exhaustruct
+	}
+
+	for range 8 {
+		logRPC("/a", log.Severity_INFO) // 1, 2, 5, 8 are written
+		logRPC("/b", log.Severity_ERROR)
+	}
+
+	logRPC("/c", log.Severity_INFO)
+
+	now = now.Add(time.Second)
+
+	logRPC("/a", log.Severity_INFO) // a new interval
+
+	if got := strings.Count(buf.String(), `"/a"`); got != 5 {
+		t.Errorf("want 5 entries of /a, got %d", got)
+	}
+
+	if got := strings.Count(buf.String(), `"/b"`); got != 8 {
+		t.Errorf("ERROR should not be sampled: got %d", got)
+	}
+
+	if got := strings.Count(buf.String(), `"/c"`); got != 1 {
+		t.Errorf("want 1 entry of /c, got %d", got)
+	}
+
+	if got := s.Suppressed(); got != 4 {
+		t.Errorf("want 4 suppressed, got %d", got)
+	}
+}
+
+func TestSampler_maxKeys(t *testing.T) {
+	t.Parallel()
+
+	clock := &fakeClock{t: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)}
+	s := log.NewSampler(time.Hour, 1, 0, log.WithSamplerClock(clock.now))
+
+	sample := func(msg string) bool {
+		return s.Sample(&log.Entry{Severity: log.Severity_INFO, Message: msg}) //nolint:exhaustruct
+	}
+
+	for i := range 10100 {
+		if !sample("msg " + strconv.Itoa(i)) {
+			t.Fatalf("want the first entry of msg %d", i)
+		}
+	}
+
+	if got := log.SampleKeys(s); got != 10000 {
+		t.Errorf("want 10000 keys, got %d", got)
+	}
+
+	if sample("msg 0") {
+		t.Error("want a counted key sampled out")
+	}
+
+	if !sample("msg 10050") || !sample("msg 10050") {
+		t.Error("want an uncounted key written")
+	}
+
+	clock.advance(time.Hour)
+
+	if !sample("fresh") || sample("fresh") {
+		t.Error("want a new key counted after the interval")
+	}
+
+	if got := log.SampleKeys(s); got != 1 {
+		t.Errorf("want the expired keys purged, got %d keys", got)
+	}
+}
@@ -0,0 +1,121 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidSeverity = errors.New("invalid severity")
+
+//nolint:cyclop
+func (s Severity) String() string {
+	switch s {
+	default:
+		fallthrough
+	case Severity_DEFAULT:
+		return "DEFAULT"
+	case Severity_DEBUG:
+		return "DEBUG"
+	case Severity_INFO:
+		return "INFO"
+	case Severity_NOTICE:
+		return "NOTICE"
+	case Severity_WARNING:
+		return "WARNING"
+	case Severity_ERROR:
+		return "ERROR"
+	case Severity_CRITICAL:
+		return "CRITICAL"
+	case Severity_ALERT:
+		return "ALERT"
+	case Severity_EMERGENCY:
+		return "EMERGENCY"
+	}
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + s.String() + `"`), nil
+}
+
+func (s *Severity) UnmarshalText(b []byte) error {
+	v, err := ParseSeverity(string(b))
+	if err != nil {
+		return err
+	}
+
+	*s = v
+
+	return nil
+}
+
+// ParseSeverity parses a severity name such as "debug" or "WARNING" (case-insensitive),
+// or the numeric value of a defined severity such as "400".
+//
+//nolint:cyclop
+func ParseSeverity(str string) (Severity, error) {
+	str = strings.TrimSpace(str)
+
+	switch strings.ToUpper(str) {
+	case "DEFAULT":
+		return Severity_DEFAULT, nil
+	case "DEBUG":
+		return Severity_DEBUG, nil
+	case "INFO":
+		return Severity_INFO, nil
+	case "NOTICE":
+		return Severity_NOTICE, nil
+	case "WARNING", "WARN":
+		return Severity_WARNING, nil
+	case "ERROR":
+		return Severity_ERROR, nil
+	case "CRITICAL":
+		return Severity_CRITICAL, nil
+	case "ALERT":
+		return Severity_ALERT, nil
+	case "EMERGENCY":
+		return Severity_EMERGENCY, nil
+	}
+
+	n, err := strconv.Atoi(str)
+	if err != nil || n < int(Severity_DEFAULT) || n > int(Severity_EMERGENCY) || n%100 != 0 { //nolint:mnd // the severities are multiples of 100
+		return Severity_DEFAULT, fmt.Errorf("%w: %q", ErrInvalidSeverity, str)
+	}
+
+	return Severity(n), nil
+}
+
+// SetLevel changes the minimum severity to be written. It is safe to call at runtime.
+func SetLevel(s Severity) {
+	Default().SetLevel(s)
+}
+
+// Level returns the minimum severity to be written.
+func Level() Severity {
+	return Default().Level()
+}
+
+// Enabled reports whether entries of severity s are written.
+func Enabled(s Severity) bool {
+	return Default().Enabled(s)
+}
+
+// SetLevelFromEnv sets the minimum severity from the environment variable key (e.g. LOG_LEVEL).
+// It does nothing if the variable is empty.
+func SetLevelFromEnv(key string) error {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil
+	}
+
+	s, err := ParseSeverity(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	SetLevel(s)
+
+	return nil
+}
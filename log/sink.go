@@ -0,0 +1,135 @@
+package log
+
+import (
+	"io"
+	"log"
+	"sync"
+)
+
+// Sink receives every entry written by a Logger, in addition to its outputs.
+// A Logger can fan out to several sinks such as NewWriterSink(os.Stderr, ...), a RotatingFile
+// and an exporter, each with its own minimum severity and format.
+// The entry must not be modified or retained after WriteEntry returns.
+type Sink interface {
+	WriteEntry(e *Entry) error
+}
+
+func WithSink(s Sink) Option {
+	return func(l *Logger) {
+		l.AddSink(s)
+	}
+}
+
+// WithOnlySinks writes entries only to sinks, not to the outputs of the Logger.
+// Use it to route every entry through sinks with their own severity and format.
+func WithOnlySinks(sinks ...Sink) Option {
+	return func(l *Logger) {
+		l.sinksOnly.Store(true)
+
+		for _, s := range sinks {
+			l.AddSink(s)
+		}
+	}
+}
+
+// AddSink adds s to the sinks of l. It is safe to call at runtime.
+func (l *Logger) AddSink(s Sink) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var sinks []Sink
+	if p := l.sinks.Load(); p != nil {
+		sinks = append(sinks, *p...)
+	}
+
+	sinks = append(sinks, s)
+	l.sinks.Store(&sinks)
+}
+
+func (l *Logger) writeSinks(e *Entry) {
+	p := l.sinks.Load()
+	if p == nil {
+		return
+	}
+
+	for _, s := range *p {
+		if err := s.WriteEntry(e); err != nil {
+			log.Printf("go-pkg/log: sink %T: %v", s, err)
+		}
+	}
+}
+
+// LevelSink passes only entries of min and above to s.
+func LevelSink(minSeverity Severity, s Sink) Sink {
+	return &levelSink{minSeverity, s}
+}
+
+type levelSink struct {
+	min Severity
+	s   Sink
+}
+
+func (ls *levelSink) WriteEntry(e *Entry) error {
+	if e.Severity < ls.min {
+		return nil
+	}
+
+	return ls.s.WriteEntry(e) //nolint:wrapcheck
+}
+
+func (ls *levelSink) Flush() error {
+	if f, ok := ls.s.(flusher); ok {
+		return f.Flush() //nolint:wrapcheck
+	}
+
+	return nil
+}
+
+// WriterSink writes entries of its minimum severity and above to w in its format,
+// one Write per entry.
+type WriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format Format
+	min    Severity
+	color  bool
+}
+
+// NewWriterSink returns a Sink writing entries of minSeverity and above to w in f.
+// Format_Console is colored if w is a terminal.
+func NewWriterSink(w io.Writer, f Format, minSeverity Severity) *WriterSink {
+	//nolint:exhaustruct
+	return &WriterSink{
+		w:      w,
+		format: f,
+		min:    minSeverity,
+		color:  isTerminal(w),
+	}
+}
+
+func (ws *WriterSink) WriteEntry(e *Entry) error {
+	if e.Severity < ws.min {
+		return nil
+	}
+
+	b, err := ws.format.appendEntry(nil, e, ws.color)
+	if err != nil {
+		return err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	_, err = ws.w.Write(b)
+
+	return err //nolint:wrapcheck
+}
+
+// Flush flushes w if it buffers entries like AsyncWriter.
+func (ws *WriterSink) Flush() error {
+	if f, ok := ws.w.(flusher); ok {
+		return f.Flush() //nolint:wrapcheck
+	}
+
+	return nil
+}
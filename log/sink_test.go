@@ -0,0 +1,48 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/taomics/go-pkg/log"
+)
+
+func TestLogger_fanOut(t *testing.T) {
+	t.Parallel()
+
+	var gcp, azure, console bytes.Buffer
+
+	l := log.New(
+		log.WithLevel(log.Severity_DEBUG),
+		log.WithOnlySinks(
+			log.NewWriterSink(&gcp, log.Format_GoogleCloud, log.Severity_INFO),
+			log.NewWriterSink(&azure, log.Format_AzureMonitor, log.Severity_ERROR),
+			log.LevelSink(log.Severity_DEBUG, log.NewWriterSink(&console, log.Format_Console, log.Severity_DEFAULT)),
+		),
+	)
+
+	l.Debugln("debug")
+	l.Println("info")
+	l.Errorln("error")
+
+	lines := strings.Split(strings.TrimSpace(gcp.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("google cloud: want 2 lines, got %q", gcp.String())
+	}
+
+	var e map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil || e["severity"] != "ERROR" {
+		t.Errorf("google cloud: want ERROR entry, got %s (%v)", lines[1], err)
+	}
+
+	e = nil
+	if err := json.Unmarshal(azure.Bytes(), &e); err != nil || e["severityLevel"] != float64(3) {
+		t.Errorf("azure: want one ERROR entry, got %s (%v)", azure.String(), err)
+	}
+
+	if n := strings.Count(console.String(), "\n"); n != 3 {
+		t.Errorf("console: want 3 lines, got %q", console.String())
+	}
+}
@@ -0,0 +1,105 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taomics/go-pkg/log"
+	"github.com/taomics/go-pkg/log/logtest"
+)
+
+func TestSourceLocation(t *testing.T) {
+	var buf bytes.Buffer
+
+	orig := log.Default()
+	defer log.SetDefault(orig)
+
+	l := log.New(
+		log.WithOutput(&buf),
+		log.WithErrorOutput(&buf),
+		log.WithStructuredLogging(true),
+		log.WithTimestamp(true),
+		log.WithSourceLocation(true),
+	)
+	log.SetDefault(l)
+
+	_, _, base, _ := runtime.Caller(0)
+	calls := []func(){
+		func() { log.Println("package") },
+		func() { l.Warningf("method") },
+		func() { log.Log(&log.Entry{Message: "package Log"}) },        //nolint:exhaustruct
+		func() { l.Log(&log.Entry{Message: "method Log"}) },           //nolint:exhaustruct
+		func() { log.LogContext(context.Background(), &log.Entry{}) }, //nolint:exhaustruct
+		func() { l.LogContext(context.Background(), &log.Entry{}) },   //nolint:exhaustruct
+		func() { log.ReportError(errors.New("package ReportError")) }, //nolint:err113
+		func() { l.ReportError(errors.New("method ReportError")) },    //nolint:err113
+	}
+
+	for i, call := range calls {
+		buf.Reset()
+
+		before := time.Now()
+		call()
+
+		var got struct {
+			Time   time.Time           `json:"time"`
+			Source *log.SourceLocation `json:"logging.googleapis.com/sourceLocation"`
+		}
+
+		if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+			t.Fatalf("%d: invalid json %q: %v", i, buf.String(), err)
+		}
+
+		if got.Time.Before(before) || got.Time.After(time.Now()) {
+			t.Errorf("%d: time %v out of range", i, got.Time)
+		}
+
+		if got.Source == nil || !strings.HasSuffix(got.Source.File, "source_test.go") || got.Source.Line != base+2+i {
+			t.Errorf("%d: want source_test.go:%d, got %+v", i, base+2+i, got.Source)
+		}
+
+		if got.Source != nil && !strings.Contains(got.Source.Function, "TestSourceLocation.func") {
+			t.Errorf("%d: want the closure, got %s", i, got.Source.Function)
+		}
+	}
+}
+
+func TestLogger_Log_reuseEntry(t *testing.T) {
+	t.Parallel()
+
+	l, rec := logtest.NewLogger(log.WithTimestamp(true), log.WithSourceLocation(true))
+	ctx := log.ContextWithTrace(context.Background(), "trace", "span")
+
+	e := &log.Entry{Message: "reused"} //nolint:exhaustruct
+
+	l.LogContext(ctx, e)
+	time.Sleep(time.Millisecond)
+	l.Log(e) // a different line
+
+	if e.Severity != log.Severity_DEFAULT || !e.Time.IsZero() || e.Source != nil || e.Trace != "" || e.SpanID != "" {
+		t.Errorf("the entry was modified: %+v", e)
+	}
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(entries))
+	}
+
+	if !entries[1].Time.After(entries[0].Time) {
+		t.Errorf("want a new time, got %v twice", entries[0].Time)
+	}
+
+	if entries[0].Source.Line == entries[1].Source.Line {
+		t.Errorf("want a new source, got line %d twice", entries[0].Source.Line)
+	}
+
+	if entries[1].Trace != "" {
+		t.Errorf("want no trace from the first context, got %q", entries[1].Trace)
+	}
+}
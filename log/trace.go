@@ -0,0 +1,112 @@
+package log
+
+import (
+	"context"
+	"strings"
+)
+
+// TraceExtractor returns the trace and span IDs of the request in ctx, or empty strings.
+// To correlate with OpenTelemetry spans, use the github.com/taomics/go-pkg/log/otel module:
+//
+//	log.SetTraceExtractor(otel.TraceFromContext)
+//
+// Cloud Logging correlates the trace only in the form projects/<project>/traces/<id>, so set the project
+// of bare trace IDs with SetTraceProject when writing Format_GoogleCloud.
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+type traceKey struct{}
+
+type traceIDs struct {
+	trace, span string
+}
+
+// ContextWithTrace returns a copy of ctx carrying the trace and span IDs for LogContext.
+func ContextWithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceIDs{traceID, spanID})
+}
+
+// TraceFromContext returns the trace and span IDs set by ContextWithTrace.
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	ids, _ := ctx.Value(traceKey{}).(traceIDs)
+
+	return ids.trace, ids.span
+}
+
+// WithTraceExtractor sets how LogContext finds the trace in a context. The default is TraceFromContext.
+func WithTraceExtractor(f TraceExtractor) Option {
+	return func(l *Logger) {
+		l.SetTraceExtractor(f)
+	}
+}
+
+// SetTraceExtractor sets the TraceExtractor of the Default Logger.
+func SetTraceExtractor(f TraceExtractor) { Default().SetTraceExtractor(f) }
+
+// SetTraceExtractor sets how LogContext finds the trace in a context. nil restores TraceFromContext.
+func (l *Logger) SetTraceExtractor(f TraceExtractor) {
+	if f == nil {
+		l.traceFrom.Store(nil)
+		return
+	}
+
+	l.traceFrom.Store(&f)
+}
+
+// WithTraceProject sets the Google Cloud project ID used to write trace IDs as
+// projects/<project>/traces/<id>, the form Cloud Logging correlates with Cloud Trace.
+func WithTraceProject(projectID string) Option {
+	return func(l *Logger) {
+		l.SetTraceProject(projectID)
+	}
+}
+
+// SetTraceProject sets the trace project of the Default Logger.
+func SetTraceProject(projectID string) { Default().SetTraceProject(projectID) }
+
+// SetTraceProject sets the Google Cloud project ID of bare trace IDs such as those extracted from
+// a context, so that they are written as projects/<project>/traces/<id>. Trace IDs already in that
+// form are written as is, and "" writes them all as is.
+func (l *Logger) SetTraceProject(projectID string) {
+	if projectID == "" {
+		l.traceProject.Store(nil)
+		return
+	}
+
+	l.traceProject.Store(&projectID)
+}
+
+// qualifyTrace returns e with its bare trace ID in the form of the trace project, if set.
+func (l *Logger) qualifyTrace(e *Entry) *Entry {
+	p := l.traceProject.Load()
+	if p == nil || e.Trace == "" || strings.HasPrefix(e.Trace, "projects/") {
+		return e
+	}
+
+	c := *e
+	c.Trace = "projects/" + *p + "/traces/" + e.Trace
+
+	return &c
+}
+
+// LogContext writes e like Log. If e has no Trace and SpanID, they are taken from ctx.
+func (l *Logger) LogContext(ctx context.Context, e *Entry) {
+	l.logContext(ctx, e)
+}
+
+func (l *Logger) logContext(ctx context.Context, e *Entry) {
+	if e != nil && e.Trace == "" && e.SpanID == "" {
+		c := *e
+		c.Trace, c.SpanID = l.extractTrace(ctx)
+		e = &c
+	}
+
+	l.log(e, calldepth)
+}
+
+func (l *Logger) extractTrace(ctx context.Context) (traceID, spanID string) {
+	if p := l.traceFrom.Load(); p != nil {
+		return (*p)(ctx)
+	}
+
+	return TraceFromContext(ctx)
+}
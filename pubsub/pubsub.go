@@ -66,6 +66,7 @@ const (
 )
 
 // MailRequest represents a request to send an email.
+// Personal data is tagged to be masked when logged with log.MaskStruct.
 type MailRequest struct {
 	MailContent
 
@@ -96,10 +97,10 @@ type MailContent struct {
 	MailID string `json:"mail_id"`
 
 	Subject      string                 `json:"subject"`
-	Body         string                 `json:"body,omitempty"`
-	HTMLBody     string                 `json:"html_body,omitempty"`
+	Body         string                 `json:"body,omitempty"          log:"mask=text"`
+	HTMLBody     string                 `json:"html_body,omitempty"     log:"mask=text"`
 	TemplateID   string                 `json:"template_id,omitempty"`
-	TemplateData map[string]interface{} `json:"template_data,omitempty"`
+	TemplateData map[string]interface{} `json:"template_data,omitempty" log:"-"`
 	Attachments  []*Attachment          `json:"attachments,omitempty"`
 }
 
@@ -115,11 +116,11 @@ type Recipient struct {
 	UserID string `json:"user_id"`
 
 	// Email is the email address of the user at this request.
-	Email string `json:"email"`
+	Email string `json:"email" log:"mask=email"`
 
 	// DisplayName is the name of the user at this request.
 	// This field might be embedded in the email template.
-	DisplayName string `json:"display_name,omitempty"` // Optional name of the recipient
+	DisplayName string `json:"display_name,omitempty" log:"mask=name"` // Optional name of the recipient
 }
 
 // Attachment represents an email attachment.
@@ -128,7 +129,7 @@ type Attachment struct {
 	Filename string `json:"filename"`
 
 	// Content is the base64-encoded content of the attachment. (Required)
-	Content string `json:"content"`
+	Content string `json:"content" log:"-"`
 
 	// Type is the MIME type of the attachment, e.g., "application/pdf" or "image/png".
 	// If not provided, it will be inferred from the filename extension.